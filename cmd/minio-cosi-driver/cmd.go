@@ -16,8 +16,12 @@ package main
 import (
 	"context"
 	"flag"
+	"io/ioutil"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -26,16 +30,40 @@ import (
 	"sigs.k8s.io/container-object-storage-interface-provisioner-sidecar/pkg/provisioner"
 
 	"sigs.k8s.io/cosi-driver-minio/pkg"
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
 )
 
 const provisionerName = "minio.objectstorage.k8s.io"
 
 var (
-	driverAddress = "unix:///var/lib/cosi/cosi.sock"
+	// version is set at build time
+	version = "unknown"
+
+	driverAddress  = "unix:///var/lib/cosi/cosi.sock"
+	metricsAddress = ""
 
 	minioAccessKey = ""
 	minioSecretKey = ""
 	minioHost      = ""
+
+	parameterAliases         = map[string]string{}
+	allowedSignatureVersions = []string{}
+	validateRequestEndpoint  = false
+	minioConnectTimeout      = 30 * time.Second
+	createGraceWindow        = time.Duration(0)
+	bestEffortOptions        = false
+	adoptExisting            = false
+	forceDelete              = false
+	defaultEncryption        = "none"
+	maxAccessPolicySize      = 64 * 1024
+	retryMaxAttempts         = 3
+	retryBaseDelay           = 500 * time.Millisecond
+	minioDisableRedirects    = false
+	minioMinTLSVersion       = "1.2"
+	minioCABundle            = ""
+	minioInsecureSkipVerify  = false
+	minioMaxIdleConns        = 0
+	minioMaxConnsPerHost     = 0
 )
 
 var cmd = &cobra.Command{
@@ -68,6 +96,12 @@ func init() {
 		driverAddress,
 		"path to unix domain socket where driver should listen")
 
+	stringFlag(&metricsAddress,
+		"metrics-addr",
+		"",
+		metricsAddress,
+		"address, e.g. :8080, where prometheus metrics are served on /metrics. Metrics are not served when empty")
+
 	stringFlag(&minioHost,
 		"minio-host",
 		"m",
@@ -86,6 +120,99 @@ func init() {
 		minioSecretKey,
 		"secret key for minio")
 
+	persistentFlags.StringToStringVar(&parameterAliases,
+		"parameter-aliases",
+		parameterAliases,
+		"additional aliases for bucket parameter keys, as alias=key pairs")
+
+	persistentFlags.StringSliceVar(&allowedSignatureVersions,
+		"allowed-signature-versions",
+		allowedSignatureVersions,
+		"s3 signature versions (S3V2, S3V4) consumers may request. Only the default (S3V4) is allowed when empty")
+
+	persistentFlags.BoolVar(&validateRequestEndpoint,
+		"validate-request-endpoint",
+		validateRequestEndpoint,
+		"reject bucket requests whose s3 endpoint does not match the minio endpoint")
+
+	persistentFlags.DurationVar(&minioConnectTimeout,
+		"minio-connect-timeout",
+		minioConnectTimeout,
+		"time allowed to connect to minio at startup, before the driver starts serving requests")
+
+	persistentFlags.DurationVar(&createGraceWindow,
+		"create-grace-window",
+		createGraceWindow,
+		"time for which the result of a bucket create is returned to identical retries")
+
+	persistentFlags.BoolVar(&bestEffortOptions,
+		"best-effort-options",
+		bestEffortOptions,
+		"create buckets even if some of the requested options fail to apply, logging the failures as warnings")
+
+	persistentFlags.BoolVar(&adoptExisting,
+		"adopt-existing",
+		adoptExisting,
+		"adopt buckets that already exist and are owned by the driver account, applying the requested options, instead of returning AlreadyExists")
+
+	persistentFlags.BoolVar(&forceDelete,
+		"force-delete",
+		forceDelete,
+		"remove all objects and object versions of buckets before deleting them")
+
+	persistentFlags.StringVarP(&defaultEncryption,
+		"default-encryption",
+		"",
+		defaultEncryption,
+		"encryption (none, SSE-S3, SSE-KMS:<key id>) of buckets created without encryption parameters")
+
+	persistentFlags.IntVar(&maxAccessPolicySize,
+		"max-access-policy-size",
+		maxAccessPolicySize,
+		"largest access policy, in bytes, accepted by grants. 0 means no limit")
+
+	persistentFlags.IntVar(&retryMaxAttempts,
+		"retry-max-attempts",
+		retryMaxAttempts,
+		"attempts made for minio calls failing with transient errors, such as timeouts, throttling or server errors, after the retries of the minio client. 1 leaves retries to the minio client")
+
+	persistentFlags.DurationVar(&retryBaseDelay,
+		"retry-base-delay",
+		retryBaseDelay,
+		"delay before the first retry of a minio call. It doubles with every further retry")
+
+	persistentFlags.BoolVar(&minioDisableRedirects,
+		"minio-disable-redirects",
+		minioDisableRedirects,
+		"fail minio requests that are answered with a redirect, instead of following it")
+
+	stringFlag(&minioMinTLSVersion,
+		"minio-min-tls-version",
+		"",
+		minioMinTLSVersion,
+		"minimum TLS version (1.2, 1.3) accepted for connections to minio")
+
+	persistentFlags.StringVarP(&minioCABundle,
+		"minio-ca-bundle",
+		"",
+		minioCABundle,
+		"path of a PEM file with CA certificates trusted, in addition to the system roots, for https connections to minio")
+
+	persistentFlags.BoolVar(&minioInsecureSkipVerify,
+		"minio-insecure-skip-verify",
+		minioInsecureSkipVerify,
+		"skip verification of the minio certificate. Only use for development")
+
+	persistentFlags.IntVar(&minioMaxIdleConns,
+		"minio-max-idle-conns",
+		minioMaxIdleConns,
+		"maximum number of idle connections kept open to minio. 0 keeps the client default")
+
+	persistentFlags.IntVar(&minioMaxConnsPerHost,
+		"minio-max-conns-per-host",
+		minioMaxConnsPerHost,
+		"maximum number of connections opened to minio. 0 means no limit")
+
 	viper.BindPFlags(cmd.PersistentFlags())
 	cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
 		if viper.IsSet(f.Name) && viper.GetString(f.Name) != "" {
@@ -94,16 +221,70 @@ func init() {
 	})
 }
 
+// clientOptions returns the options of the MinIO
+// client as configured through flags
+func clientOptions() (minio.ClientOptions, error) {
+	opts := minio.ClientOptions{
+		AppVersion:         version,
+		DisableRedirects:   minioDisableRedirects,
+		MinTLSVersion:      minioMinTLSVersion,
+		InsecureSkipVerify: minioInsecureSkipVerify,
+		MaxIdleConns:       minioMaxIdleConns,
+		MaxConnsPerHost:    minioMaxConnsPerHost,
+	}
+	if minioCABundle != "" {
+		caBundle, err := ioutil.ReadFile(minioCABundle)
+		if err != nil {
+			return opts, errors.Wrap(err, "reading minio CA bundle failed")
+		}
+		opts.CABundle = caBundle
+	}
+	return opts, nil
+}
+
 func run(ctx context.Context, args []string) error {
+	clientOpts, err := clientOptions()
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+
 	identityServer, bucketProvisioner, err := pkg.NewDriver(ctx,
 		provisionerName,
 		minioHost,
 		minioAccessKey,
-		minioSecretKey)
+		minioSecretKey,
+		pkg.Options{
+			ParameterAliases:         parameterAliases,
+			AllowedSignatureVersions: allowedSignatureVersions,
+			ValidateRequestEndpoint:  validateRequestEndpoint,
+			ConnectTimeout:           minioConnectTimeout,
+			CreateGraceWindow:        createGraceWindow,
+			BestEffortOptions:        bestEffortOptions,
+			AdoptExisting:            adoptExisting,
+			ForceDelete:              forceDelete,
+			DefaultEncryption:        defaultEncryption,
+			MaxAccessPolicySize:      maxAccessPolicySize,
+			Registry:                 registry,
+			ClientOptions:            clientOpts,
+			Retry: pkg.RetryPolicy{
+				MaxAttempts: retryMaxAttempts,
+				BaseDelay:   retryBaseDelay,
+			},
+		})
 	if err != nil {
 		return err
 	}
 
+	if metricsAddress != "" {
+		serveMetrics(ctx, metricsAddress, bucketProvisioner.Handler())
+	}
+
 	server, err := provisioner.NewDefaultCOSIProvisionerServer(driverAddress,
 		identityServer,
 		bucketProvisioner)
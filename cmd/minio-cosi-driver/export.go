@@ -0,0 +1,92 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
+)
+
+var (
+	inventoryFile         = "inventory.json"
+	inventoryBucketPrefix = ""
+)
+
+var exportInventoryCmd = &cobra.Command{
+	Use:           "export-inventory",
+	Short:         "Export the configuration of the buckets provisioned with a bucket prefix as JSON",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportInventory(cmd.Context(), args)
+	},
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	exportInventoryCmd.Flags().StringVarP(&inventoryFile,
+		"output",
+		"o",
+		inventoryFile,
+		"path of the file the inventory is written to")
+
+	exportInventoryCmd.Flags().StringVarP(&inventoryBucketPrefix,
+		"bucket-prefix",
+		"",
+		inventoryBucketPrefix,
+		"bucketPrefix parameter of the buckets to export. Buckets whose names do not start with it are not managed by the driver")
+	exportInventoryCmd.MarkFlagRequired("bucket-prefix")
+
+	cmd.AddCommand(exportInventoryCmd)
+}
+
+func exportInventory(ctx context.Context, args []string) error {
+	// An empty prefix would export every bucket
+	// of the server, not only managed ones
+	if inventoryBucketPrefix == "" {
+		return errors.New("bucket prefix cannot be empty")
+	}
+
+	opts, err := clientOptions()
+	if err != nil {
+		return err
+	}
+	mc, err := minio.NewClient(ctx, minioHost, minioAccessKey, minioSecretKey, opts)
+	if err != nil {
+		return err
+	}
+
+	configs, err := mc.ListBucketConfigs(ctx, inventoryBucketPrefix)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(configs, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding inventory failed")
+	}
+	if err := ioutil.WriteFile(inventoryFile, out, 0644); err != nil {
+		return errors.Wrap(err, "writing inventory failed")
+	}
+
+	klog.InfoS("Exported bucket inventory", "buckets", len(configs), "file", inventoryFile)
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
+)
+
+var supportedParametersCmd = &cobra.Command{
+	Use:           "supported-parameters",
+	Short:         "Print the bucket parameters understood by the driver as JSON",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Aliases configured through flags are listed
+		// along with those built into the driver
+		aliases := map[string]string{}
+		for k, v := range minio.ParameterAliases {
+			aliases[k] = v
+		}
+		for k, v := range parameterAliases {
+			aliases[k] = v
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(minio.ParametersWithAliases(aliases))
+	},
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	cmd.AddCommand(supportedParametersCmd)
+}
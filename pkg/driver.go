@@ -15,20 +15,175 @@ package pkg
 
 import (
 	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
 
 	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
 )
 
-func NewDriver(ctx context.Context, provisioner, minioHost, accessKey, secretKey string) (*IdentityServer, *ProvisionerServer, error) {
-	mc, err := minio.NewClient(ctx, minioHost, accessKey, secretKey)
+// Options holds the driver settings that are not needed
+// to establish the connection to MinIO
+type Options struct {
+	// ParameterAliases maps additional parameter keys to the
+	// keys understood by the driver. These are applied on top
+	// of minio.ParameterAliases
+	ParameterAliases map[string]string
+
+	// AllowedSignatureVersions lists the S3 signature versions,
+	// e.g. S3V4, that consumers may request. When empty, only
+	// the default signature version is allowed
+	AllowedSignatureVersions []string
+
+	// ValidateRequestEndpoint rejects create requests whose
+	// s3 endpoint does not match the MinIO endpoint of the driver
+	ValidateRequestEndpoint bool
+
+	// ConnectTimeout bounds the time spent establishing and
+	// validating the connection to MinIO at startup. Zero
+	// means no timeout
+	ConnectTimeout time.Duration
+
+	// CreateGraceWindow is how long the result of a create is
+	// handed out to identical requests after it completed
+	CreateGraceWindow time.Duration
+
+	// BestEffortOptions lets bucket creation succeed when some
+	// of the requested options fail to apply. The failures are
+	// logged as warnings
+	BestEffortOptions bool
+
+	// AdoptExisting makes bucket creation succeed for buckets
+	// that already exist, reconciling their options with the
+	// request, rather than returning codes.AlreadyExists
+	AdoptExisting bool
+
+	// ForceDelete removes all objects, object versions and
+	// delete markers of a bucket before deleting it
+	ForceDelete bool
+
+	// DefaultEncryption is the encryption, given as none, SSE-S3
+	// or SSE-KMS:<key id>, of buckets created without encryption
+	// parameters
+	DefaultEncryption string
+
+	// MaxAccessPolicySize is the largest access policy, in bytes,
+	// accepted by grants. Zero means no limit
+	MaxAccessPolicySize int
+
+	// Registry is the registry the provisioner metrics are
+	// registered on. A new registry is used when nil
+	Registry *prometheus.Registry
+
+	// Retry bounds the retries of MinIO calls that fail with
+	// transient errors. The zero value leaves retries to the
+	// MinIO client
+	Retry RetryPolicy
+
+	// ClientOptions configures the connection to MinIO
+	ClientOptions minio.ClientOptions
+}
+
+func NewDriver(ctx context.Context, provisioner, minioHost, accessKey, secretKey string, opts Options) (*IdentityServer, *ProvisionerServer, error) {
+	// Options are checked ahead of connecting, so
+	// that invalid settings are reported at once
+	ps, err := newProvisionerServer(provisioner, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	connectCtx := ctx
+	if opts.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		connectCtx, cancel = context.WithTimeout(ctx, opts.ConnectTimeout)
+		defer cancel()
+	}
+	mc, err := minio.NewClient(connectCtx, minioHost, accessKey, secretKey, opts.ClientOptions)
 	if err != nil {
 		return nil, nil, err
 	}
+	ps.mc = mc
 
 	return &IdentityServer{
-			provisioner: provisioner,
-		}, &ProvisionerServer{
-			provisioner: provisioner,
-			mc:          mc,
-		}, nil
+		provisioner: provisioner,
+	}, ps, nil
+}
+
+// NewProvisionerServer returns a provisioner that uses the given
+// client, e.g. a fake one, instead of connecting to MinIO. The
+// connection settings of the options are validated, but not used
+func NewProvisionerServer(provisioner string, mc minio.BucketClient, opts Options) (*ProvisionerServer, error) {
+	ps, err := newProvisionerServer(provisioner, opts)
+	if err != nil {
+		return nil, err
+	}
+	ps.mc = mc
+	return ps, nil
+}
+
+// newProvisionerServer returns a provisioner configured
+// through the options, without a MinIO client
+func newProvisionerServer(provisioner string, opts Options) (*ProvisionerServer, error) {
+	if err := opts.ClientOptions.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid connection settings")
+	}
+
+	signatureVersions := map[cosi.S3SignatureVersion]bool{}
+	for _, v := range opts.AllowedSignatureVersions {
+		sv, ok := cosi.S3SignatureVersion_value[v]
+		if !ok || sv == int32(cosi.S3SignatureVersion_UnknownSignature) {
+			return nil, errors.Errorf("invalid signature version %q", v)
+		}
+		signatureVersions[cosi.S3SignatureVersion(sv)] = true
+	}
+
+	defaultEncryption, err := minio.ParseEncryption(opts.DefaultEncryption)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid default encryption")
+	}
+
+	aliases := map[string]string{}
+	for k, v := range minio.ParameterAliases {
+		aliases[k] = v
+	}
+	for k, v := range opts.ParameterAliases {
+		aliases[k] = v
+	}
+	// Aliases are checked here rather than when a request is
+	// parsed, so that a mistyped alias is reported at once
+	for alias, key := range aliases {
+		if !minio.IsSupportedParameter(key) {
+			return nil, errors.Errorf("parameter alias %s refers to unknown parameter %s", alias, key)
+		}
+		if minio.IsSupportedParameter(alias) {
+			return nil, errors.Errorf("parameter alias %s is a parameter itself", alias)
+		}
+	}
+
+	registry := opts.Registry
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+	}
+	m, err := newMetrics(registry)
+	if err != nil {
+		return nil, errors.Wrap(err, "registering metrics failed")
+	}
+
+	return &ProvisionerServer{
+		provisioner:              provisioner,
+		parameterAliases:         aliases,
+		allowedSignatureVersions: signatureVersions,
+		validateRequestEndpoint:  opts.ValidateRequestEndpoint,
+		bestEffortOptions:        opts.BestEffortOptions,
+		adoptExisting:            opts.AdoptExisting,
+		forceDelete:              opts.ForceDelete,
+		defaultEncryption:        defaultEncryption,
+		maxAccessPolicySize:      opts.MaxAccessPolicySize,
+		retry:                    opts.Retry,
+		createInflight:           newInflight(opts.CreateGraceWindow),
+		metrics:                  m,
+	}, nil
 }
@@ -0,0 +1,82 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inflight deduplicates identical calls. A call arriving while
+// an identical one is in progress, or within the grace window
+// after it completed, waits for and returns the result of
+// that call instead of running again. Only successes and
+// AlreadyExists errors are kept for the grace window, so that
+// retries of other failures run again
+type inflight struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+func newInflight(window time.Duration) *inflight {
+	return &inflight{
+		window: window,
+		calls:  map[string]*inflightCall{},
+	}
+}
+
+func (f *inflight) Do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	f.mu.Lock()
+	if c, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, c.err
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	c := &inflightCall{
+		done: make(chan struct{}),
+	}
+	f.calls[key] = c
+	f.mu.Unlock()
+
+	c.val, c.err = fn()
+	close(c.done)
+
+	forget := func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+	}
+	if f.window > 0 && (c.err == nil || status.Code(c.err) == codes.AlreadyExists) {
+		time.AfterFunc(f.window, forget)
+	} else {
+		forget()
+	}
+	return c.val, c.err
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInflightSharesResult(t *testing.T) {
+	f := newInflight(time.Minute)
+
+	calls := 0
+	release := make(chan struct{})
+	fn := func() (interface{}, error) {
+		calls++
+		<-release
+		return "bucket", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = f.Do(context.Background(), "key", fn)
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected fn to run once, ran %d times", calls)
+	}
+	for i, r := range results {
+		if r != "bucket" {
+			t.Errorf("call %d: expected result %q, got %v", i, "bucket", r)
+		}
+	}
+}
+
+func TestInflightKeepsResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{
+			name:      "success is kept",
+			wantCalls: 1,
+		},
+		{
+			name:      "AlreadyExists is kept",
+			err:       status.Error(codes.AlreadyExists, "Bucket already exists"),
+			wantCalls: 1,
+		},
+		{
+			name:      "Internal is retried",
+			err:       status.Error(codes.Internal, "Bucket creation failed"),
+			wantCalls: 2,
+		},
+		{
+			name:      "Unavailable is retried",
+			err:       status.Error(codes.Unavailable, "request time too skewed"),
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newInflight(time.Minute)
+
+			calls := 0
+			fn := func() (interface{}, error) {
+				calls++
+				return nil, tt.err
+			}
+			for i := 0; i < 2; i++ {
+				if _, err := f.Do(context.Background(), "key", fn); status.Code(err) != status.Code(tt.err) {
+					t.Errorf("call %d: expected code %s, got %s", i, status.Code(tt.err), status.Code(err))
+				}
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("expected fn to run %d times, ran %d times", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestInflightWithoutWindow(t *testing.T) {
+	f := newInflight(0)
+
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		return nil, nil
+	}
+	f.Do(context.Background(), "key", fn)
+	f.Do(context.Background(), "key", fn)
+
+	if calls != 2 {
+		t.Errorf("expected fn to run twice, ran %d times", calls)
+	}
+}
+
+func TestInflightCancelledWaiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func() (context.Context, context.CancelFunc)
+		wantCode codes.Code
+	}{
+		{
+			name:     "cancelled",
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "deadline exceeded",
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithTimeout(context.Background(), 0) },
+			wantCode: codes.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newInflight(0)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			defer close(release)
+			go f.Do(context.Background(), "key", func() (interface{}, error) {
+				close(started)
+				<-release
+				return "bucket", nil
+			})
+			<-started
+
+			ctx, cancel := tt.ctx()
+			cancel()
+			_, err := f.Do(ctx, "key", func() (interface{}, error) {
+				t.Error("expected the waiter not to run fn")
+				return nil, nil
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("expected code %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
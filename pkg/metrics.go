@@ -0,0 +1,80 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/status"
+)
+
+const metricsNamespace = "cosi_driver_minio"
+
+// Operations whose outcome and latency are recorded
+const (
+	operationCreate = "create"
+	operationDelete = "delete"
+	operationGrant  = "grant"
+	operationRevoke = "revoke"
+)
+
+// metrics records the outcome and latency of the provisioner
+// operations, labeled by operation and gRPC status code
+type metrics struct {
+	registry *prometheus.Registry
+
+	operations *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+// newMetrics registers the collectors of the provisioner on the registry
+func newMetrics(registry *prometheus.Registry) (*metrics, error) {
+	m := &metrics{
+		registry: registry,
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "operations_total",
+			Help:      "Number of provisioner operations, by operation and gRPC status code.",
+		}, []string{"operation", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of provisioner operations, by operation and gRPC status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "code"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.operations, m.duration} {
+		if err := registry.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// observe records an operation that started at the given time
+// and failed with *err, if not nil. It is meant to be deferred
+func (m *metrics) observe(operation string, start time.Time, err *error) {
+	code := status.Code(*err).String()
+	m.operations.WithLabelValues(operation, code).Inc()
+	m.duration.WithLabelValues(operation, code).Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the metrics of the provisioner in the Prometheus format
+func (s *ProvisionerServer) Handler() http.Handler {
+	return promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	s, _ := newTestServer(t, Options{Registry: registry})
+
+	resp, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", nil))
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest("", nil)); err == nil {
+		t.Fatalf("expected create without a bucket name to fail")
+	}
+	if _, err := s.ProvisionerGrantBucketAccess(context.Background(), &cosi.ProvisionerGrantBucketAccessRequest{
+		BucketId: resp.BucketId,
+	}); err != nil {
+		t.Fatalf("grant failed: %v", err)
+	}
+	if _, err := s.ProvisionerDeleteBucket(context.Background(), &cosi.ProvisionerDeleteBucketRequest{
+		BucketId: resp.BucketId,
+	}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	want := []struct {
+		operation string
+		code      string
+	}{
+		{operationCreate, "OK"},
+		{operationCreate, "InvalidArgument"},
+		{operationGrant, "OK"},
+		{operationDelete, "OK"},
+	}
+	for _, w := range want {
+		counter := s.metrics.operations.WithLabelValues(w.operation, w.code)
+		if got := testutil.ToFloat64(counter); got != 1 {
+			t.Errorf("expected 1 %s operation with code %s, got %v", w.operation, w.code, got)
+		}
+	}
+	if got := testutil.CollectAndCount(s.metrics.operations, metricsNamespace+"_operations_total"); got != len(want) {
+		t.Errorf("expected %d operation series, got %d", len(want), got)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics failed: %v", err)
+	}
+	samples := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != metricsNamespace+"_operation_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			samples[labels["operation"]+"/"+labels["code"]] = m.GetHistogram().GetSampleCount()
+		}
+	}
+	for _, w := range want {
+		if got := samples[w.operation+"/"+w.code]; got != 1 {
+			t.Errorf("expected 1 %s latency sample with code %s, got %d", w.operation, w.code, got)
+		}
+	}
+}
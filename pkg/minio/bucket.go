@@ -15,22 +15,149 @@ package minio
 
 import (
 	"context"
+	"net"
+	"strings"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/pkg/errors"
 )
 
 var ErrBucketAlreadyExists = errors.New("Bucket Already Exists")
+var ErrBucketAlreadyOwnedByYou = errors.New("Bucket Already Owned By You")
+var ErrBucketNotFound = errors.New("Bucket Not Found")
+var ErrBucketNotEmpty = errors.New("Bucket Not Empty")
 
 type MakeBucketOptions minio.MakeBucketOptions
 
+// S3 limits on the length of bucket names
+const (
+	MinBucketNameLength = 3
+	MaxBucketNameLength = 63
+)
+
+// ValidateBucketName checks the bucket name against the S3 naming
+// rules for DNS-compliant bucket names
+func ValidateBucketName(name string) error {
+	if len(name) < MinBucketNameLength || len(name) > MaxBucketNameLength {
+		return errors.Errorf("bucket name must be between %d and %d characters long", MinBucketNameLength, MaxBucketNameLength)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-') {
+			return errors.Errorf("bucket name can only contain lowercase letters, digits, dots and hyphens, found %q", r)
+		}
+	}
+	if !isAlphanumeric(name[0]) || !isAlphanumeric(name[len(name)-1]) {
+		return errors.New("bucket name must begin and end with a letter or digit")
+	}
+	if strings.Contains(name, "..") {
+		return errors.New("bucket name cannot contain consecutive dots")
+	}
+	if strings.Contains(name, ".-") || strings.Contains(name, "-.") {
+		return errors.New("bucket name cannot contain a dot next to a hyphen")
+	}
+	if net.ParseIP(name) != nil {
+		return errors.New("bucket name cannot be an IP address")
+	}
+	return nil
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// CreateBucket creates the bucket and returns its bucket ID. The bucket ID
+// is the bucket name itself, so it does not depend on any runtime state
+// such as the resolved region, and remains stable across driver restarts.
+// ErrBucketAlreadyOwnedByYou is returned if the bucket exists and is owned
+// by the driver's account, ErrBucketAlreadyExists if another account owns it
 func (x *C) CreateBucket(ctx context.Context, bucketName string, options MakeBucketOptions) (string, error) {
 	if err := x.client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions(options)); err != nil {
-		errCode := minio.ToErrorResponse(err).Code
-		if errCode == "BucketAlreadyExists" || errCode == "BucketAlreadyOwnedByYou" {
+		switch minio.ToErrorResponse(err).Code {
+		case "BucketAlreadyOwnedByYou":
+			return bucketName, ErrBucketAlreadyOwnedByYou
+		case "BucketAlreadyExists":
 			return bucketName, ErrBucketAlreadyExists
 		}
 		return "", err
 	}
 	return bucketName, nil
 }
+
+// SetBucketVersioning enables or suspends versioning of the bucket
+func (x *C) SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	if enabled {
+		return x.client.EnableVersioning(ctx, bucketName)
+	}
+	return x.client.SuspendVersioning(ctx, bucketName)
+}
+
+// DeleteBucket deletes the bucket, which must be empty
+func (x *C) DeleteBucket(ctx context.Context, bucketName string) error {
+	if err := x.client.RemoveBucket(ctx, bucketName); err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case "NoSuchBucket":
+			return ErrBucketNotFound
+		case "BucketNotEmpty":
+			return ErrBucketNotEmpty
+		}
+		return err
+	}
+	return nil
+}
+
+// EmptyBucket removes all objects of the bucket, including
+// all object versions and delete markers. Nothing is removed if
+// an object is under legal hold, ErrObjectUnderLegalHold is
+// returned instead
+func (x *C) EmptyBucket(ctx context.Context, bucketName string) error {
+	if err := x.checkLegalHolds(ctx, bucketName); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objectsCh := make(chan minio.ObjectInfo)
+	listErrCh := make(chan error, 1)
+	go func() {
+		defer close(objectsCh)
+		opts := minio.ListObjectsOptions{
+			WithVersions: true,
+			Recursive:    true,
+		}
+		for object := range x.client.ListObjects(ctx, bucketName, opts) {
+			if object.Err != nil {
+				listErrCh <- object.Err
+				return
+			}
+			select {
+			case objectsCh <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The error channel is drained completely, so
+	// that the removal does not block on it
+	var removeErr error
+	for e := range x.client.RemoveObjects(ctx, bucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		if removeErr == nil {
+			removeErr = errors.Wrapf(e.Err, "removing object %s (version %s) failed", e.ObjectName, e.VersionID)
+			cancel()
+		}
+	}
+
+	if removeErr != nil {
+		return removeErr
+	}
+	select {
+	case err := <-listErrCh:
+		if minio.ToErrorResponse(err).Code == "NoSuchBucket" {
+			return ErrBucketNotFound
+		}
+		return errors.Wrap(err, "listing objects failed")
+	default:
+	}
+	return nil
+}
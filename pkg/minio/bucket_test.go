@@ -0,0 +1,51 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBucketName(t *testing.T) {
+	tests := []struct {
+		name    string
+		bucket  string
+		wantErr bool
+	}{
+		{name: "valid", bucket: "my-bucket.1"},
+		{name: "minimum length", bucket: "abc"},
+		{name: "maximum length", bucket: strings.Repeat("a", 63)},
+		{name: "too short", bucket: "ab", wantErr: true},
+		{name: "too long", bucket: strings.Repeat("a", 64), wantErr: true},
+		{name: "uppercase", bucket: "My-Bucket", wantErr: true},
+		{name: "underscore", bucket: "my_bucket", wantErr: true},
+		{name: "leading hyphen", bucket: "-bucket", wantErr: true},
+		{name: "trailing hyphen", bucket: "bucket-", wantErr: true},
+		{name: "leading dot", bucket: ".bucket", wantErr: true},
+		{name: "consecutive dots", bucket: "my..bucket", wantErr: true},
+		{name: "dot before hyphen", bucket: "my.-bucket", wantErr: true},
+		{name: "hyphen before dot", bucket: "my-.bucket", wantErr: true},
+		{name: "ip address", bucket: "192.168.1.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBucketName(tt.bucket)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBucketName(%q) = %v, want error %v", tt.bucket, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -15,9 +15,14 @@ package minio
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"net/url"
+	"strings"
+	"syscall"
 
 	"github.com/google/uuid"
+	"github.com/minio/madmin-go"
 	"github.com/pkg/errors"
 
 	min "github.com/minio/minio-go/v7"
@@ -26,15 +31,50 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// appName identifies the driver in the User-Agent
+// of requests sent to MinIO
+const appName = "cosi-driver-minio"
+
+// ClientOptions holds the optional settings of
+// the connection to MinIO
+type ClientOptions struct {
+	// AppVersion is the driver version reported, along with the
+	// driver name, in the User-Agent of requests sent to MinIO
+	AppVersion string
+
+	// DisableRedirects fails requests that MinIO answers
+	// with a redirect, instead of following it
+	DisableRedirects bool
+
+	// MinTLSVersion is the minimum TLS version, 1.2 or 1.3,
+	// accepted for connections to MinIO. Defaults to 1.2
+	MinTLSVersion string
+	// CABundle holds PEM encoded certificates trusted, in addition
+	// to the system roots, when verifying the MinIO certificate.
+	// It requires an https endpoint
+	CABundle []byte
+	// InsecureSkipVerify disables verification of the MinIO
+	// certificate. It must only be used for development
+	InsecureSkipVerify bool
+
+	// MaxIdleConns limits the idle connections kept open to
+	// MinIO. Zero keeps the default of the MinIO client
+	MaxIdleConns int
+	// MaxConnsPerHost limits the connections opened to MinIO,
+	// counting those in use. Zero means no limit
+	MaxConnsPerHost int
+}
+
 type C struct {
 	accessKey string
 	secretKey string
 	host      *url.URL
 
 	client *min.Client
+	admin  *madmin.AdminClient
 }
 
-func NewClient(ctx context.Context, minioHost, accessKey, secretKey string) (*C, error) {
+func NewClient(ctx context.Context, minioHost, accessKey, secretKey string, opts ClientOptions) (*C, error) {
 	if minioHost == "" {
 		return nil, errors.New("minio host cannot be empty")
 	}
@@ -52,18 +92,38 @@ func NewClient(ctx context.Context, minioHost, accessKey, secretKey string) (*C,
 		return nil, errors.New("invalid url scheme for minio endpoint")
 	}
 
-	clChan := make(chan *min.Client)
-	errChan := make(chan error)
+	transport, err := newTransport(secure, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The admin client shares the transport, and with it the
+	// TLS and connection settings, of the S3 client
+	admin, err := madmin.New(host.Host, accessKey, secretKey, secure)
+	if err != nil {
+		return nil, err
+	}
+	admin.SetCustomTransport(transport)
+	admin.SetAppInfo(appName, opts.AppVersion)
+
+	// buffered, so that the goroutine does not leak when
+	// the context is done before the connection is validated
+	clChan := make(chan *min.Client, 1)
+	errChan := make(chan error, 1)
 	go func() {
 		klog.V(3).InfoS("Connecting to MinIO", "endpoint", host.Host)
 
 		cl, err := min.New(host.Host, &min.Options{
-			Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-			Secure: secure,
+			Creds:     credentials.NewStaticV4(accessKey, secretKey, ""),
+			Secure:    secure,
+			Transport: transport,
 		})
 		if err != nil {
 			errChan <- err
+			return
 		}
+		cl.SetAppInfo(appName, opts.AppVersion)
+
 		_, err = cl.BucketExists(ctx, uuid.New().String())
 		if err != nil {
 			if errResp, ok := err.(min.ErrorResponse); ok {
@@ -94,8 +154,102 @@ func NewClient(ctx context.Context, minioHost, accessKey, secretKey string) (*C,
 			host:      host,
 
 			client: cl,
+			admin:  admin,
 		}, nil
 	case err := <-errChan:
 		return nil, err
 	}
 }
+
+// MatchesEndpoint reports whether the given endpoint refers to the
+// MinIO server the client is connected to. Endpoints without a
+// scheme are assumed to use the scheme of the client. Schemes and
+// hosts are compared case-insensitively, default ports and trailing
+// slashes are ignored
+func (x *C) MatchesEndpoint(endpoint string) bool {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = x.host.Scheme + "://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	return normalizeEndpoint(u) == normalizeEndpoint(x.host)
+}
+
+// defaultPorts are the ports endpoints use when they have none
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// normalizeEndpoint returns the endpoint in lower case, with the
+// default port of its scheme and without trailing slashes
+func normalizeEndpoint(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[scheme]
+	}
+	host := net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+	return scheme + "://" + host + strings.TrimRight(u.Path, "/")
+}
+
+// IsRequestTimeTooSkewed reports whether MinIO rejected a request
+// because the clocks of the driver and the server are too far apart
+func IsRequestTimeTooSkewed(err error) bool {
+	return min.ToErrorResponse(errors.Cause(err)).Code == "RequestTimeTooSkewed"
+}
+
+// retryableCodes and retryableStatusCodes are the S3 error codes
+// and HTTP status codes the MinIO client retries requests on
+var (
+	retryableCodes = map[string]bool{
+		"RequestError":          true,
+		"RequestTimeout":        true,
+		"Throttling":            true,
+		"ThrottlingException":   true,
+		"RequestLimitExceeded":  true,
+		"RequestThrottled":      true,
+		"InternalError":         true,
+		"ExpiredToken":          true,
+		"ExpiredTokenException": true,
+		"SlowDown":              true,
+	}
+	retryableStatusCodes = map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+	}
+)
+
+// IsTransient reports whether a request failed in a way that is likely
+// to go away when it is retried. These are the failures the MinIO client
+// retries requests on: network errors, throttling and server errors.
+// Client errors, such as AlreadyExists, are not transient
+func IsTransient(err error) bool {
+	err = errors.Cause(err)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	errResp := min.ToErrorResponse(err)
+	return retryableCodes[errResp.Code] || retryableStatusCodes[errResp.StatusCode]
+}
+
+// IsTransportError reports whether a request failed without a response
+// from MinIO, e.g. because the connection was lost or timed out. Unlike
+// a refused connection, such a request may have been carried out
+func IsTransportError(err error) bool {
+	err = errors.Cause(err)
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	min "github.com/minio/minio-go/v7"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Put", URL: "http://minio:9000/bucket", Err: syscall.ECONNREFUSED},
+			want: true,
+		},
+		{
+			name: "lost response",
+			err:  &url.Error{Op: "Put", URL: "http://minio:9000/bucket", Err: io.ErrUnexpectedEOF},
+			want: true,
+		},
+		{
+			name: "throttled",
+			err:  min.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable},
+			want: true,
+		},
+		{
+			name: "too many requests",
+			err:  min.ErrorResponse{Code: "TooManyRequests", StatusCode: http.StatusTooManyRequests},
+			want: true,
+		},
+		{
+			name: "request timeout",
+			err:  min.ErrorResponse{Code: "RequestTimeout", StatusCode: http.StatusBadRequest},
+			want: true,
+		},
+		{
+			name: "server error",
+			err:  errors.Wrap(min.ErrorResponse{Code: "InternalError", StatusCode: http.StatusInternalServerError}, "creating bucket failed"),
+			want: true,
+		},
+		{
+			name: "bucket exists",
+			err:  min.ErrorResponse{Code: "BucketAlreadyOwnedByYou", StatusCode: http.StatusConflict},
+		},
+		{
+			name: "access denied",
+			err:  min.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden},
+		},
+		{
+			name: "context canceled",
+			err:  &url.Error{Op: "Put", URL: "http://minio:9000/bucket", Err: context.Canceled},
+		},
+		{
+			name: "other error",
+			err:  errors.New("boom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("expected transient %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsTransportError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "lost response",
+			err:  &url.Error{Op: "Put", URL: "http://minio:9000/bucket", Err: io.ErrUnexpectedEOF},
+			want: true,
+		},
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Put", URL: "http://minio:9000/bucket", Err: syscall.ECONNREFUSED},
+		},
+		{
+			name: "server error",
+			err:  min.ErrorResponse{Code: "InternalError", StatusCode: http.StatusInternalServerError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransportError(tt.err); got != tt.want {
+				t.Errorf("expected transport error %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchesEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		endpoint string
+		want     bool
+	}{
+		{
+			name:     "same endpoint",
+			host:     "http://minio:9000",
+			endpoint: "http://minio:9000",
+			want:     true,
+		},
+		{
+			name:     "without scheme",
+			host:     "http://minio:9000",
+			endpoint: "minio:9000",
+			want:     true,
+		},
+		{
+			name:     "upper case",
+			host:     "http://minio:9000",
+			endpoint: "HTTP://MINIO:9000",
+			want:     true,
+		},
+		{
+			name:     "other scheme",
+			host:     "http://minio:9000",
+			endpoint: "https://minio:9000",
+			want:     false,
+		},
+		{
+			name:     "trailing slash",
+			host:     "http://minio:9000",
+			endpoint: "http://minio:9000/",
+			want:     true,
+		},
+		{
+			name:     "default http port",
+			host:     "http://minio",
+			endpoint: "http://minio:80",
+			want:     true,
+		},
+		{
+			name:     "default https port",
+			host:     "https://minio:443/",
+			endpoint: "https://minio",
+			want:     true,
+		},
+		{
+			name:     "default port of the other scheme",
+			host:     "https://minio",
+			endpoint: "https://minio:80",
+			want:     false,
+		},
+		{
+			name:     "other port",
+			host:     "http://minio:9000",
+			endpoint: "http://minio:9001",
+			want:     false,
+		},
+		{
+			name:     "other host",
+			host:     "http://minio:9000",
+			endpoint: "http://other:9000",
+			want:     false,
+		},
+		{
+			name:     "other path",
+			host:     "http://minio:9000",
+			endpoint: "http://minio:9000/other",
+			want:     false,
+		},
+		{
+			name:     "invalid endpoint",
+			host:     "http://minio:9000",
+			endpoint: "http://minio:port",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, err := url.Parse(tt.host)
+			if err != nil {
+				t.Fatalf("parsing host failed: %v", err)
+			}
+			x := &C{host: host}
+			if got := x.MatchesEndpoint(tt.endpoint); got != tt.want {
+				t.Errorf("expected %v for endpoint %q, got %v", tt.want, tt.endpoint, got)
+			}
+		})
+	}
+}
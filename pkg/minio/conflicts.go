@@ -0,0 +1,64 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"fmt"
+)
+
+// parameterValue matches a bucket parameter. An empty
+// value matches the parameter whatever its value is
+type parameterValue struct {
+	key   string
+	value string
+}
+
+func (p parameterValue) matches(parameters map[string]string) bool {
+	v, ok := parameters[p.key]
+	return ok && (p.value == "" || p.value == v)
+}
+
+func (p parameterValue) String() string {
+	if p.value == "" {
+		return p.key
+	}
+	return fmt.Sprintf("%s=%s", p.key, p.value)
+}
+
+// parameterConflicts lists the bucket parameters
+// that cannot be requested together
+var parameterConflicts = [][2]parameterValue{
+	// object locking requires versioning to stay enabled
+	{
+		{key: ObjectLocking},
+		{key: Versioning, value: VersioningSuspended},
+	},
+	// noncurrent versions only exist on versioned buckets
+	{
+		{key: NoncurrentExpireDays},
+		{key: Versioning, value: VersioningSuspended},
+	},
+}
+
+// parameterConflictErrors describes every conflicting
+// pair of parameters, if any
+func parameterConflictErrors(parameters map[string]string) []string {
+	conflicts := []string{}
+	for _, c := range parameterConflicts {
+		if c[0].matches(parameters) && c[1].matches(parameters) {
+			conflicts = append(conflicts, fmt.Sprintf("parameters %s and %s cannot be used together", c[0], c[1]))
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParameterConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		wantErr    string
+	}{
+		{
+			name: "object locking with suspended versioning",
+			parameters: map[string]string{
+				ObjectLocking: "true",
+				Versioning:    VersioningSuspended,
+			},
+			wantErr: "parameters " + ObjectLocking + " and " + Versioning + "=" + VersioningSuspended + " cannot be used together",
+		},
+		{
+			name: "noncurrent expiry with suspended versioning",
+			parameters: map[string]string{
+				NoncurrentExpireDays: "7",
+				Versioning:           VersioningSuspended,
+			},
+			wantErr: "parameters " + NoncurrentExpireDays + " and " + Versioning + "=" + VersioningSuspended + " cannot be used together",
+		},
+		{
+			name: "object locking with enabled versioning",
+			parameters: map[string]string{
+				ObjectLocking: "true",
+				Versioning:    VersioningEnabled,
+			},
+		},
+		{
+			name: "noncurrent expiry with enabled versioning",
+			parameters: map[string]string{
+				NoncurrentExpireDays: "7",
+				Versioning:           VersioningEnabled,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBucketParameters(tt.parameters)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -15,4 +15,60 @@ package minio
 
 const (
 	ObjectLocking = "objectlocking.min.io"
+
+	// ObjectLockMode and ObjectLockDays set the default
+	// retention of buckets created with ObjectLocking
+	ObjectLockMode = "objectLockMode"
+	ObjectLockDays = "objectLockDays"
+
+	// Versioning sets the versioning state of the
+	// bucket, either VersioningEnabled or VersioningSuspended
+	Versioning          = "versioning"
+	VersioningEnabled   = "enabled"
+	VersioningSuspended = "suspended"
+
+	// BucketPrefix is prepended to the requested
+	// bucket name to form the name of the bucket
+	BucketPrefix = "bucketPrefix"
+
+	// PlaceholderObject is the key of an object created
+	// in the bucket right after it is provisioned
+	PlaceholderObject = "placeholderObject"
+	// PlaceholderObjectContent is the optional content
+	// of the placeholder object
+	PlaceholderObjectContent = "placeholderObjectContent"
+
+	// MaxPlaceholderObjectContent is the maximum size, in
+	// bytes, of the content of the placeholder object
+	MaxPlaceholderObjectContent = 4096
+
+	// Encryption sets the default server-side encryption
+	// of the bucket, either SSES3 or SSEKMS. SSE-KMS also
+	// requires the KMS key id to be given as KMSKeyID
+	Encryption = "encryption"
+	KMSKeyID   = "kmsKeyId"
+
+	// TagPrefix is the prefix of parameters setting bucket
+	// tags, e.g. tag-team: platform sets the tag team
+	TagPrefix = "tag-"
+
+	// LifecycleExpiryDays is the number of days after
+	// which current object versions expire
+	LifecycleExpiryDays = "lifecycleExpiryDays"
+
+	// Quota is the hard limit on the size of the bucket,
+	// given in bytes or with a size suffix, e.g. 5Gi
+	Quota = "quota"
+
+	// NoncurrentExpireDays is the number of days after which
+	// noncurrent object versions of a versioned bucket expire
+	NoncurrentExpireDays = "lifecycle.noncurrentExpireDays"
 )
+
+// ParameterAliases maps the alternate spellings of parameter keys
+// commonly used by BucketClass authors to the keys understood
+// by the driver
+var ParameterAliases = map[string]string{
+	"objectLocking":  ObjectLocking,
+	"object-locking": ObjectLocking,
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/sse"
+	"github.com/pkg/errors"
+)
+
+// Supported types of default server-side encryption
+const (
+	SSES3  = "SSE-S3"
+	SSEKMS = "SSE-KMS"
+)
+
+// EncryptionConfig is the default server-side encryption of a bucket
+type EncryptionConfig struct {
+	Type string
+	// KMSKeyID is the KMS key used with SSE-KMS
+	KMSKeyID string
+}
+
+// Validate checks that the encryption type is supported, and
+// that a KMS key is given with SSE-KMS only
+func (c EncryptionConfig) Validate() error {
+	switch c.Type {
+	case SSES3:
+		if c.KMSKeyID != "" {
+			return errors.Errorf("a KMS key id cannot be used with %s", SSES3)
+		}
+	case SSEKMS:
+		if c.KMSKeyID == "" {
+			return errors.Errorf("a KMS key id is required with %s", SSEKMS)
+		}
+	default:
+		return errors.Errorf("unsupported encryption %q, must be %s or %s", c.Type, SSES3, SSEKMS)
+	}
+	return nil
+}
+
+// ParseEncryption parses an encryption config given as none,
+// SSE-S3 or SSE-KMS:<key id>. none yields the zero config
+func ParseEncryption(s string) (EncryptionConfig, error) {
+	if s == "" || s == "none" {
+		return EncryptionConfig{}, nil
+	}
+	cfg := EncryptionConfig{Type: s}
+	if i := strings.Index(s, ":"); i >= 0 {
+		cfg = EncryptionConfig{Type: s[:i], KMSKeyID: s[i+1:]}
+	}
+	return cfg, cfg.Validate()
+}
+
+// SetBucketEncryption sets the default server-side encryption of the bucket
+func (x *C) SetBucketEncryption(ctx context.Context, bucketName string, cfg EncryptionConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	config := sse.NewConfigurationSSES3()
+	if cfg.Type == SSEKMS {
+		config = sse.NewConfigurationSSEKMS(cfg.KMSKeyID)
+	}
+	return x.client.SetBucketEncryption(ctx, bucketName, config)
+}
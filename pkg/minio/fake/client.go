@@ -0,0 +1,332 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides an in-memory minio.BucketClient, so that
+// the provisioner can be exercised without a MinIO server
+package fake
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
+)
+
+// Bucket is the state of a bucket held by the fake client
+type Bucket struct {
+	Options    minio.MakeBucketOptions
+	Encryption minio.EncryptionConfig
+	Tags       map[string]string
+	Versioning bool
+
+	ObjectLockMode string
+	ObjectLockDays int
+
+	ExpiryDays           int
+	NoncurrentExpiryDays int
+
+	Quota uint64
+
+	Objects map[string][]byte
+	// LegalHolds holds the names of objects under legal hold
+	LegalHolds map[string]bool
+}
+
+// Failure is an error returned by a call to CreateBucket
+type Failure struct {
+	Err error
+	// Applied creates the bucket before failing, as
+	// when the response of MinIO is lost
+	Applied bool
+}
+
+// Client is an in-memory minio.BucketClient. Buckets can be seeded
+// and inspected through Buckets. Errors holds errors returned by the
+// operation of the given name, e.g. CreateBucket, instead of running it.
+// CreateFailures holds failures returned, one per call and in order,
+// by CreateBucket before it runs normally. Calls counts the calls
+// of each operation
+type Client struct {
+	Endpoint       string
+	Buckets        map[string]*Bucket
+	Errors         map[string]error
+	CreateFailures []Failure
+	Calls          map[string]int
+
+	mu sync.Mutex
+}
+
+var (
+	_ minio.BucketClient    = &Client{}
+	_ minio.InventoryClient = &Client{}
+)
+
+// NewClient returns a fake client for the given endpoint, without buckets
+func NewClient(endpoint string) *Client {
+	return &Client{
+		Endpoint: endpoint,
+		Buckets:  map[string]*Bucket{},
+		Errors:   map[string]error{},
+		Calls:    map[string]int{},
+	}
+}
+
+// bucket returns the bucket, or the error configured for the
+// operation, or minio.ErrBucketNotFound if the bucket is missing
+func (c *Client) bucket(op, bucketName string) (*Bucket, error) {
+	c.Calls[op]++
+	if err := c.Errors[op]; err != nil {
+		return nil, err
+	}
+	b, ok := c.Buckets[bucketName]
+	if !ok {
+		return nil, minio.ErrBucketNotFound
+	}
+	return b, nil
+}
+
+func (c *Client) MatchesEndpoint(endpoint string) bool {
+	return endpoint == c.Endpoint
+}
+
+func (c *Client) CreateBucket(ctx context.Context, bucketName string, options minio.MakeBucketOptions) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Calls["CreateBucket"]++
+	if err := c.Errors["CreateBucket"]; err != nil {
+		return "", err
+	}
+	if len(c.CreateFailures) > 0 {
+		f := c.CreateFailures[0]
+		c.CreateFailures = c.CreateFailures[1:]
+		if f.Applied {
+			c.createBucket(bucketName, options)
+		}
+		return "", f.Err
+	}
+	if _, ok := c.Buckets[bucketName]; ok {
+		return bucketName, minio.ErrBucketAlreadyOwnedByYou
+	}
+	c.createBucket(bucketName, options)
+	return bucketName, nil
+}
+
+// createBucket adds the bucket, unless it exists already
+func (c *Client) createBucket(bucketName string, options minio.MakeBucketOptions) {
+	if _, ok := c.Buckets[bucketName]; ok {
+		return
+	}
+	c.Buckets[bucketName] = &Bucket{
+		Options: options,
+		Objects: map[string][]byte{},
+	}
+}
+
+func (c *Client) DeleteBucket(ctx context.Context, bucketName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("DeleteBucket", bucketName)
+	if err != nil {
+		return err
+	}
+	if len(b.Objects) > 0 {
+		return minio.ErrBucketNotEmpty
+	}
+	delete(c.Buckets, bucketName)
+	return nil
+}
+
+func (c *Client) EmptyBucket(ctx context.Context, bucketName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("EmptyBucket", bucketName)
+	if err != nil {
+		return err
+	}
+	for name, held := range b.LegalHolds {
+		if _, ok := b.Objects[name]; ok && held {
+			return minio.ErrObjectUnderLegalHold
+		}
+	}
+	b.Objects = map[string][]byte{}
+	return nil
+}
+
+func (c *Client) SetBucketEncryption(ctx context.Context, bucketName string, cfg minio.EncryptionConfig) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetBucketEncryption", bucketName)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	b.Encryption = cfg
+	return nil
+}
+
+func (c *Client) SetObjectLockConfig(ctx context.Context, bucketName, mode string, days int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetObjectLockConfig", bucketName)
+	if err != nil {
+		return err
+	}
+	if err := minio.ValidateObjectLockRetention(mode, days); err != nil {
+		return err
+	}
+	b.ObjectLockMode = mode
+	b.ObjectLockDays = days
+	return nil
+}
+
+func (c *Client) SetBucketTags(ctx context.Context, bucketName string, t map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetBucketTags", bucketName)
+	if err != nil {
+		return err
+	}
+	b.Tags = map[string]string{}
+	for k, v := range t {
+		b.Tags[k] = v
+	}
+	return nil
+}
+
+func (c *Client) SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetBucketVersioning", bucketName)
+	if err != nil {
+		return err
+	}
+	b.Versioning = enabled
+	return nil
+}
+
+func (c *Client) SetBucketLifecycle(ctx context.Context, bucketName string, expiryDays int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetBucketLifecycle", bucketName)
+	if err != nil {
+		return err
+	}
+	b.ExpiryDays = expiryDays
+	return nil
+}
+
+func (c *Client) SetNoncurrentVersionExpiry(ctx context.Context, bucketName string, days int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetNoncurrentVersionExpiry", bucketName)
+	if err != nil {
+		return err
+	}
+	if !b.Versioning {
+		return minio.ErrVersioningNotEnabled
+	}
+	b.NoncurrentExpiryDays = days
+	return nil
+}
+
+func (c *Client) SetBucketQuota(ctx context.Context, bucketName string, bytes uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("SetBucketQuota", bucketName)
+	if err != nil {
+		return err
+	}
+	b.Quota = bytes
+	return nil
+}
+
+func (c *Client) ObjectExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("ObjectExists", bucketName)
+	if err != nil {
+		return false, err
+	}
+	_, ok := b.Objects[objectName]
+	return ok, nil
+}
+
+func (c *Client) PutObject(ctx context.Context, bucketName, objectName string, content []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := c.bucket("PutObject", bucketName)
+	if err != nil {
+		return err
+	}
+	b.Objects[objectName] = append([]byte(nil), content...)
+	return nil
+}
+
+func (c *Client) ListBucketConfigs(ctx context.Context, prefix string) ([]minio.BucketConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Calls["ListBucketConfigs"]++
+	if err := c.Errors["ListBucketConfigs"]; err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range c.Buckets {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	configs := []minio.BucketConfig{}
+	for _, name := range names {
+		b := c.Buckets[name]
+		config := minio.BucketConfig{
+			Name:                 name,
+			Region:               b.Options.Region,
+			Tags:                 b.Tags,
+			ObjectLocking:        b.Options.ObjectLocking,
+			ObjectLockMode:       b.ObjectLockMode,
+			ObjectLockDays:       b.ObjectLockDays,
+			LifecycleExpiryDays:  b.ExpiryDays,
+			NoncurrentExpireDays: b.NoncurrentExpiryDays,
+			Quota:                b.Quota,
+		}
+		if b.Versioning {
+			config.Versioning = minio.VersioningEnabled
+		}
+		if b.Encryption != (minio.EncryptionConfig{}) {
+			encryption := b.Encryption
+			config.Encryption = &encryption
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+)
+
+// BucketClient is the set of MinIO operations the provisioner
+// depends on. It is implemented by C, and by the fake package
+// for use without a MinIO server
+type BucketClient interface {
+	MatchesEndpoint(endpoint string) bool
+
+	CreateBucket(ctx context.Context, bucketName string, options MakeBucketOptions) (string, error)
+	DeleteBucket(ctx context.Context, bucketName string) error
+	EmptyBucket(ctx context.Context, bucketName string) error
+
+	SetBucketEncryption(ctx context.Context, bucketName string, cfg EncryptionConfig) error
+	SetObjectLockConfig(ctx context.Context, bucketName, mode string, days int) error
+	SetBucketTags(ctx context.Context, bucketName string, t map[string]string) error
+	SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error
+	SetBucketLifecycle(ctx context.Context, bucketName string, expiryDays int) error
+	SetNoncurrentVersionExpiry(ctx context.Context, bucketName string, days int) error
+	SetBucketQuota(ctx context.Context, bucketName string, bytes uint64) error
+
+	ObjectExists(ctx context.Context, bucketName, objectName string) (bool, error)
+	PutObject(ctx context.Context, bucketName, objectName string, content []byte) error
+}
+
+// InventoryClient is the set of read-only MinIO operations
+// the inventory export depends on
+type InventoryClient interface {
+	ListBucketConfigs(ctx context.Context, prefix string) ([]BucketConfig, error)
+}
+
+var (
+	_ BucketClient    = &C{}
+	_ InventoryClient = &C{}
+)
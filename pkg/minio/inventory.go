@@ -0,0 +1,206 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+)
+
+// BucketConfig is the resolved configuration of a bucket
+// as read back from MinIO
+type BucketConfig struct {
+	Name          string            `json:"name"`
+	Region        string            `json:"region,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	ObjectLocking bool              `json:"objectLocking"`
+	Policy        string            `json:"policy,omitempty"`
+
+	ObjectLockMode string            `json:"objectLockMode,omitempty"`
+	ObjectLockDays int               `json:"objectLockDays,omitempty"`
+	Versioning     string            `json:"versioning,omitempty"`
+	Encryption     *EncryptionConfig `json:"encryption,omitempty"`
+
+	LifecycleExpiryDays  int    `json:"lifecycleExpiryDays,omitempty"`
+	NoncurrentExpireDays int    `json:"noncurrentExpireDays,omitempty"`
+	Quota                uint64 `json:"quota,omitempty"`
+}
+
+// Parameters returns the bucket parameters that provision a bucket with
+// the configuration, given the prefix of its name. The region is given
+// through the protocol, and the policy through grants, so they are not
+// among the parameters
+func (c BucketConfig) Parameters(prefix string) map[string]string {
+	parameters := map[string]string{}
+	if prefix != "" {
+		parameters[BucketPrefix] = prefix
+	}
+	if c.ObjectLocking {
+		parameters[ObjectLocking] = "true"
+	}
+	if c.ObjectLockMode != "" {
+		parameters[ObjectLockMode] = c.ObjectLockMode
+		parameters[ObjectLockDays] = strconv.Itoa(c.ObjectLockDays)
+	}
+	if c.Versioning != "" {
+		parameters[Versioning] = c.Versioning
+	}
+	if c.Encryption != nil {
+		parameters[Encryption] = c.Encryption.Type
+		if c.Encryption.KMSKeyID != "" {
+			parameters[KMSKeyID] = c.Encryption.KMSKeyID
+		}
+	}
+	for k, v := range c.Tags {
+		parameters[TagPrefix+k] = v
+	}
+	if c.LifecycleExpiryDays > 0 {
+		parameters[LifecycleExpiryDays] = strconv.Itoa(c.LifecycleExpiryDays)
+	}
+	if c.NoncurrentExpireDays > 0 {
+		parameters[NoncurrentExpireDays] = strconv.Itoa(c.NoncurrentExpireDays)
+	}
+	if c.Quota > 0 {
+		parameters[Quota] = strconv.FormatUint(c.Quota, 10)
+	}
+	return parameters
+}
+
+// ListBucketConfigs reads the configuration of the buckets whose names
+// start with the prefix, as given through the BucketPrefix parameter.
+// It only issues read requests against MinIO
+func (x *C) ListBucketConfigs(ctx context.Context, prefix string) ([]BucketConfig, error) {
+	buckets, err := x.client.ListBuckets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing buckets failed")
+	}
+
+	names := []string{}
+	for _, b := range buckets {
+		if strings.HasPrefix(b.Name, prefix) {
+			names = append(names, b.Name)
+		}
+	}
+	sort.Strings(names)
+
+	configs := make([]BucketConfig, 0, len(names))
+	for _, name := range names {
+		config, err := x.GetBucketConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// GetBucketConfig reads the region, tags, object locking, policy,
+// versioning, encryption, managed lifecycle rules and quota of the
+// given bucket
+func (x *C) GetBucketConfig(ctx context.Context, bucketName string) (BucketConfig, error) {
+	config := BucketConfig{
+		Name: bucketName,
+	}
+
+	region, err := x.client.GetBucketLocation(ctx, bucketName)
+	if err != nil {
+		return config, errors.Wrapf(err, "reading location of bucket %s failed", bucketName)
+	}
+	config.Region = region
+
+	t, err := x.client.GetBucketTagging(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchTagSet" {
+			return config, errors.Wrapf(err, "reading tags of bucket %s failed", bucketName)
+		}
+	} else {
+		config.Tags = t.ToMap()
+	}
+
+	objectLock, mode, validity, unit, err := x.client.GetObjectLockConfig(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "ObjectLockConfigurationNotFoundError" {
+			return config, errors.Wrapf(err, "reading object lock config of bucket %s failed", bucketName)
+		}
+	}
+	config.ObjectLocking = objectLock == "Enabled"
+	// Only retention in days is set by the driver
+	if mode != nil && validity != nil && unit != nil && *unit == minio.Days {
+		config.ObjectLockMode = string(*mode)
+		config.ObjectLockDays = int(*validity)
+	}
+
+	policy, err := x.client.GetBucketPolicy(ctx, bucketName)
+	if err != nil {
+		return config, errors.Wrapf(err, "reading policy of bucket %s failed", bucketName)
+	}
+	config.Policy = policy
+
+	versioning, err := x.client.GetBucketVersioning(ctx, bucketName)
+	if err != nil {
+		return config, errors.Wrapf(err, "reading versioning of bucket %s failed", bucketName)
+	}
+	switch versioning.Status {
+	case minio.Enabled:
+		config.Versioning = VersioningEnabled
+	case minio.Suspended:
+		config.Versioning = VersioningSuspended
+	}
+
+	encryption, err := x.client.GetBucketEncryption(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "ServerSideEncryptionConfigurationNotFoundError" {
+			return config, errors.Wrapf(err, "reading encryption of bucket %s failed", bucketName)
+		}
+	} else if len(encryption.Rules) > 0 {
+		switch apply := encryption.Rules[0].Apply; apply.SSEAlgorithm {
+		case "AES256":
+			config.Encryption = &EncryptionConfig{Type: SSES3}
+		case "aws:kms":
+			config.Encryption = &EncryptionConfig{Type: SSEKMS, KMSKeyID: apply.KmsMasterKeyID}
+		}
+	}
+
+	// Only the lifecycle rules managed by the driver are read
+	lifecycleConfig, err := x.client.GetBucketLifecycle(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchLifecycleConfiguration" {
+			return config, errors.Wrapf(err, "reading lifecycle of bucket %s failed", bucketName)
+		}
+	} else {
+		for _, r := range lifecycleConfig.Rules {
+			switch r.ID {
+			case expiryRuleID:
+				config.LifecycleExpiryDays = int(r.Expiration.Days)
+			case noncurrentExpiryRuleID:
+				config.NoncurrentExpireDays = int(r.NoncurrentVersionExpiration.NoncurrentDays)
+			}
+		}
+	}
+
+	quota, err := x.admin.GetBucketQuota(ctx, bucketName)
+	if err != nil {
+		if err = adminError(err); minio.ToErrorResponse(err).Code != "XMinioAdminNoSuchQuotaConfiguration" {
+			return config, errors.Wrapf(err, "reading quota of bucket %s failed", bucketName)
+		}
+	}
+	config.Quota = quota.Quota
+
+	return config, nil
+}
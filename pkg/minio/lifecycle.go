@@ -0,0 +1,129 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/pkg/errors"
+)
+
+// IDs of the lifecycle rules managed by the driver
+const (
+	expiryRuleID           = "cosi-expiry"
+	noncurrentExpiryRuleID = "cosi-noncurrent-expiry"
+)
+
+var ErrVersioningNotEnabled = errors.New("Bucket Versioning Not Enabled")
+
+// SetBucketLifecycle installs a lifecycle rule that expires
+// current object versions after the given number of days.
+// Zero days removes the rule
+func (x *C) SetBucketLifecycle(ctx context.Context, bucketName string, expiryDays int) error {
+	if expiryDays == 0 {
+		return x.removeLifecycleRule(ctx, bucketName, expiryRuleID)
+	}
+	return x.setLifecycleRule(ctx, bucketName, expiryRule(expiryDays))
+}
+
+// expiryRule returns the rule expiring current object
+// versions after the given number of days
+func expiryRule(days int) lifecycle.Rule {
+	return lifecycle.Rule{
+		ID:     expiryRuleID,
+		Status: minio.Enabled,
+		Expiration: lifecycle.Expiration{
+			Days: lifecycle.ExpirationDays(days),
+		},
+	}
+}
+
+// SetNoncurrentVersionExpiry installs a lifecycle rule that expires
+// noncurrent object versions after the given number of days. The
+// bucket must have versioning enabled
+func (x *C) SetNoncurrentVersionExpiry(ctx context.Context, bucketName string, days int) error {
+	versioning, err := x.client.GetBucketVersioning(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	if versioning.Status != minio.Enabled {
+		return ErrVersioningNotEnabled
+	}
+
+	return x.setLifecycleRule(ctx, bucketName, noncurrentExpiryRule(days))
+}
+
+// noncurrentExpiryRule returns the rule expiring noncurrent
+// object versions after the given number of days
+func noncurrentExpiryRule(days int) lifecycle.Rule {
+	return lifecycle.Rule{
+		ID:     noncurrentExpiryRuleID,
+		Status: minio.Enabled,
+		NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
+			NoncurrentDays: lifecycle.ExpirationDays(days),
+		},
+	}
+}
+
+// setLifecycleRule adds the rule to the lifecycle configuration of
+// the bucket. A rule with the same ID is replaced rather than
+// duplicated, and all other rules are left untouched
+func (x *C) setLifecycleRule(ctx context.Context, bucketName string, rule lifecycle.Rule) error {
+	config, err := x.client.GetBucketLifecycle(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchLifecycleConfiguration" {
+			return err
+		}
+		config = lifecycle.NewConfiguration()
+	}
+
+	config.Rules = append(withoutRule(config.Rules, rule.ID), rule)
+
+	return x.client.SetBucketLifecycle(ctx, bucketName, config)
+}
+
+// removeLifecycleRule removes the rule of the given ID from the
+// lifecycle configuration of the bucket, if it has one. All other
+// rules are left untouched
+func (x *C) removeLifecycleRule(ctx context.Context, bucketName, ruleID string) error {
+	config, err := x.client.GetBucketLifecycle(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchLifecycleConfiguration" {
+			return nil
+		}
+		return err
+	}
+
+	rules := withoutRule(config.Rules, ruleID)
+	if len(rules) == len(config.Rules) {
+		return nil
+	}
+	config.Rules = rules
+
+	// A configuration left without rules is deleted
+	return x.client.SetBucketLifecycle(ctx, bucketName, config)
+}
+
+// withoutRule returns the rules other than the one of the given ID
+func withoutRule(rules []lifecycle.Rule, ruleID string) []lifecycle.Rule {
+	others := []lifecycle.Rule{}
+	for _, r := range rules {
+		if r.ID != ruleID {
+			others = append(others, r)
+		}
+	}
+	return others
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func TestExpiryRule(t *testing.T) {
+	rule := expiryRule(30)
+
+	if rule.ID != "cosi-expiry" {
+		t.Errorf("expected rule ID %q, got %q", "cosi-expiry", rule.ID)
+	}
+	if rule.Status != minio.Enabled {
+		t.Errorf("expected rule status %q, got %q", minio.Enabled, rule.Status)
+	}
+	if rule.Expiration.Days != 30 {
+		t.Errorf("expected expiration after 30 days, got %d", rule.Expiration.Days)
+	}
+	if !rule.NoncurrentVersionExpiration.IsDaysNull() {
+		t.Errorf("expected no noncurrent version expiration, got %+v", rule.NoncurrentVersionExpiration)
+	}
+}
+
+func TestNoncurrentExpiryRule(t *testing.T) {
+	rule := noncurrentExpiryRule(7)
+
+	if rule.ID != "cosi-noncurrent-expiry" {
+		t.Errorf("expected rule ID %q, got %q", "cosi-noncurrent-expiry", rule.ID)
+	}
+	if rule.Status != minio.Enabled {
+		t.Errorf("expected rule status %q, got %q", minio.Enabled, rule.Status)
+	}
+	if rule.NoncurrentVersionExpiration.NoncurrentDays != 7 {
+		t.Errorf("expected noncurrent version expiration after 7 days, got %d", rule.NoncurrentVersionExpiration.NoncurrentDays)
+	}
+	if !rule.Expiration.IsDaysNull() {
+		t.Errorf("expected no current version expiration, got %+v", rule.Expiration)
+	}
+}
+
+func TestRemoveExpiryRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		lifecycle  string
+		wantMethod string
+	}{
+		{
+			name: "other rules kept",
+			lifecycle: `<LifecycleConfiguration>` +
+				`<Rule><ID>cosi-expiry</ID><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule>` +
+				`<Rule><ID>cosi-noncurrent-expiry</ID><Status>Enabled</Status><NoncurrentVersionExpiration><NoncurrentDays>7</NoncurrentDays></NoncurrentVersionExpiration></Rule>` +
+				`</LifecycleConfiguration>`,
+			wantMethod: http.MethodPut,
+		},
+		{
+			name: "last rule removed",
+			lifecycle: `<LifecycleConfiguration>` +
+				`<Rule><ID>cosi-expiry</ID><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule>` +
+				`</LifecycleConfiguration>`,
+			wantMethod: http.MethodDelete,
+		},
+		{
+			name: "no expiry rule",
+			lifecycle: `<LifecycleConfiguration>` +
+				`<Rule><ID>other</ID><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule>` +
+				`</LifecycleConfiguration>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, body string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(tt.lifecycle))
+					return
+				}
+				b, _ := ioutil.ReadAll(r.Body)
+				method, body = r.Method, string(b)
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer srv.Close()
+
+			client, err := minio.New(strings.TrimPrefix(srv.URL, "http://"), &minio.Options{
+				Creds:  credentials.NewStaticV4("access", "secret", ""),
+				Region: "us-east-1",
+			})
+			if err != nil {
+				t.Fatalf("creating client failed: %v", err)
+			}
+			x := &C{client: client}
+
+			if err := x.SetBucketLifecycle(context.Background(), "bucket", 0); err != nil {
+				t.Fatalf("removing expiry rule failed: %v", err)
+			}
+			if method != tt.wantMethod {
+				t.Fatalf("expected lifecycle %q request, got %q", tt.wantMethod, method)
+			}
+			if strings.Contains(body, expiryRuleID+"<") {
+				t.Errorf("expected the expiry rule to be removed, got %s", body)
+			}
+			if tt.wantMethod == http.MethodPut && !strings.Contains(body, noncurrentExpiryRuleID) {
+				t.Errorf("expected the other rules to be kept, got %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// ObjectExists reports whether the object is present in the bucket
+func (x *C) ObjectExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	if _, err := x.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// PutObject uploads the content as an object in the bucket,
+// replacing the object if it is already present
+func (x *C) PutObject(ctx context.Context, bucketName, objectName string, content []byte) error {
+	_, err := x.client.PutObject(ctx, bucketName, objectName, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{})
+	return err
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+)
+
+var ErrObjectUnderLegalHold = errors.New("Object Under Legal Hold")
+
+// ValidateObjectLockRetention checks that the default
+// retention can be applied to an object locked bucket
+func ValidateObjectLockRetention(mode string, days int) error {
+	if !minio.RetentionMode(mode).IsValid() {
+		return errors.Errorf("invalid retention mode %q, must be %s or %s", mode, minio.Governance, minio.Compliance)
+	}
+	if days <= 0 {
+		return errors.New("retention days must be a positive integer")
+	}
+	return nil
+}
+
+// SetObjectLockConfig sets the default retention of the
+// bucket, which must have been created with object locking
+func (x *C) SetObjectLockConfig(ctx context.Context, bucketName, mode string, days int) error {
+	if err := ValidateObjectLockRetention(mode, days); err != nil {
+		return err
+	}
+
+	retentionMode := minio.RetentionMode(mode)
+	validity := uint(days)
+	unit := minio.Days
+	return x.client.SetObjectLockConfig(ctx, bucketName, &retentionMode, &validity, &unit)
+}
+
+// checkLegalHolds returns ErrObjectUnderLegalHold if any object
+// version of the bucket is under legal hold. Only buckets created
+// with object locking can hold such objects
+func (x *C) checkLegalHolds(ctx context.Context, bucketName string) error {
+	objectLock, _, _, _, err := x.client.GetObjectLockConfig(ctx, bucketName)
+	if err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case "ObjectLockConfigurationNotFoundError":
+			return nil
+		case "NoSuchBucket":
+			return ErrBucketNotFound
+		}
+		return errors.Wrap(err, "reading object lock config failed")
+	}
+	if objectLock != "Enabled" {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := minio.ListObjectsOptions{
+		WithVersions: true,
+		Recursive:    true,
+	}
+	for object := range x.client.ListObjects(ctx, bucketName, opts) {
+		if object.Err != nil {
+			return errors.Wrap(object.Err, "listing objects failed")
+		}
+		if object.IsDeleteMarker {
+			continue
+		}
+
+		held, err := underLegalHold(x.client.GetObjectLegalHold(ctx, bucketName, object.Key, minio.GetObjectLegalHoldOptions{
+			VersionID: object.VersionID,
+		}))
+		if err != nil {
+			return errors.Wrapf(err, "reading legal hold of object %s (version %s) failed", object.Key, object.VersionID)
+		}
+		if held {
+			klog.InfoS("Object is under legal hold", "bucket", bucketName, "object", object.Key, "version", object.VersionID)
+			return ErrObjectUnderLegalHold
+		}
+	}
+	return nil
+}
+
+// notHeldCodes are the error codes MinIO answers legal hold requests
+// with for objects that are not under legal hold: objects that never
+// had one, and versions removed since they were listed
+var notHeldCodes = map[string]bool{
+	"NoSuchObjectLockConfiguration": true,
+	"NoSuchKey":                     true,
+	"NoSuchVersion":                 true,
+}
+
+// underLegalHold interprets the answer to a legal hold request
+func underLegalHold(legalHold *minio.LegalHoldStatus, err error) (bool, error) {
+	if err != nil {
+		if notHeldCodes[minio.ToErrorResponse(err).Code] {
+			return false, nil
+		}
+		return false, err
+	}
+	return legalHold != nil && *legalHold == minio.LegalHoldEnabled, nil
+}
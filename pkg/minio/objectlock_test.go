@@ -0,0 +1,72 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestUnderLegalHold(t *testing.T) {
+	on, off := minio.LegalHoldEnabled, minio.LegalHoldDisabled
+
+	tests := []struct {
+		name      string
+		legalHold *minio.LegalHoldStatus
+		err       error
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:      "held",
+			legalHold: &on,
+			want:      true,
+		},
+		{
+			name:      "released",
+			legalHold: &off,
+		},
+		{
+			name: "never held",
+			err:  minio.ErrorResponse{Code: "NoSuchObjectLockConfiguration", StatusCode: http.StatusNotFound},
+		},
+		{
+			name: "object removed",
+			err:  minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound},
+		},
+		{
+			name: "version removed",
+			err:  minio.ErrorResponse{Code: "NoSuchVersion", StatusCode: http.StatusNotFound},
+		},
+		{
+			name:    "access denied",
+			err:     minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			held, err := underLegalHold(tt.legalHold, tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if held != tt.want {
+				t.Errorf("expected held %v, got %v", tt.want, held)
+			}
+		})
+	}
+}
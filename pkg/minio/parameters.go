@@ -0,0 +1,304 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParameterInfo describes a bucket parameter understood by the driver
+type ParameterInfo struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Values      string   `json:"values,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+
+	// prefix, when set, makes the parameter match all keys
+	// starting with it, followed by a name
+	prefix string
+	// parse sets the parameter of the given key on the bucket
+	// parameters, or returns why its value is invalid
+	parse func(p *BucketParameters, key, value string) error
+}
+
+// SupportedParameters lists the bucket parameters understood by the
+// driver. ParseBucketParameters parses the parameters through it, so
+// that keys missing from it are rejected
+var SupportedParameters = []ParameterInfo{
+	{
+		Key:         BucketPrefix,
+		Description: "prefix prepended to the requested bucket name",
+		Values:      fmt.Sprintf("combined name of %d to %d characters", MinBucketNameLength, MaxBucketNameLength),
+		parse: func(p *BucketParameters, key, value string) error {
+			p.BucketPrefix = value
+			return nil
+		},
+	},
+	{
+		Key:         ObjectLocking,
+		Description: "create the bucket with object locking enabled",
+		Values:      "any value",
+		parse: func(p *BucketParameters, key, value string) error {
+			p.ObjectLocking = true
+			return nil
+		},
+	},
+	{
+		Key:         ObjectLockMode,
+		Description: "default retention mode of an object locked bucket, requires " + ObjectLockDays,
+		Values:      "GOVERNANCE or COMPLIANCE",
+		parse: func(p *BucketParameters, key, value string) error {
+			p.ObjectLockMode = value
+			return nil
+		},
+	},
+	{
+		Key:         ObjectLockDays,
+		Description: "default retention days of an object locked bucket, requires " + ObjectLockMode,
+		Values:      "positive integer",
+		parse: func(p *BucketParameters, key, value string) error {
+			days, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.Errorf("%s must be an integer", key)
+			}
+			p.ObjectLockDays = days
+			return nil
+		},
+	},
+	{
+		Key:         Versioning,
+		Description: "versioning state of the bucket",
+		Values:      VersioningEnabled + " or " + VersioningSuspended,
+		parse: func(p *BucketParameters, key, value string) error {
+			if value != VersioningEnabled && value != VersioningSuspended {
+				return errors.Errorf("%s must be %s or %s", key, VersioningEnabled, VersioningSuspended)
+			}
+			p.Versioning = value
+			return nil
+		},
+	},
+	{
+		Key:         Encryption,
+		Description: "default server-side encryption of the bucket",
+		Values:      SSES3 + " or " + SSEKMS,
+		parse: func(p *BucketParameters, key, value string) error {
+			p.Encryption.Type = value
+			return nil
+		},
+	},
+	{
+		Key:         KMSKeyID,
+		Description: "KMS key used with " + SSEKMS,
+		Values:      "KMS key id",
+		parse: func(p *BucketParameters, key, value string) error {
+			p.Encryption.KMSKeyID = value
+			return nil
+		},
+	},
+	{
+		Key:         TagPrefix + "<key>",
+		Description: "sets the bucket tag <key>",
+		Values:      fmt.Sprintf("up to %d characters, keys up to %d characters", MaxTagValueLength, MaxTagKeyLength),
+		prefix:      TagPrefix,
+		parse: func(p *BucketParameters, key, value string) error {
+			p.Tags[strings.TrimPrefix(key, TagPrefix)] = value
+			return nil
+		},
+	},
+	{
+		Key:         LifecycleExpiryDays,
+		Description: "days after which current object versions expire, 0 for never, removing the expiry of adopted buckets",
+		Values:      "non-negative integer",
+		parse: func(p *BucketParameters, key, value string) error {
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 0 {
+				return errors.Errorf("%s must be a non-negative integer", key)
+			}
+			p.LifecycleExpiryDays = days
+			return nil
+		},
+	},
+	{
+		Key:         NoncurrentExpireDays,
+		Description: "days after which noncurrent object versions expire, requires " + Versioning + "=" + VersioningEnabled + " or " + ObjectLocking,
+		Values:      "positive integer",
+		parse: func(p *BucketParameters, key, value string) error {
+			days, err := strconv.Atoi(value)
+			if err != nil || days <= 0 {
+				return errors.Errorf("%s must be a positive integer", key)
+			}
+			p.NoncurrentExpireDays = days
+			return nil
+		},
+	},
+	{
+		Key:         Quota,
+		Description: "hard limit on the size of the bucket",
+		Values:      "positive size in bytes, optionally with a suffix such as Ki, Mi, Gi, Ti, k, M, G or T",
+		parse: func(p *BucketParameters, key, value string) error {
+			bytes, err := ParseQuota(value)
+			if err != nil {
+				return errors.Errorf("%s: %v", key, err)
+			}
+			p.Quota = bytes
+			return nil
+		},
+	},
+	{
+		Key:         PlaceholderObject,
+		Description: "key of an object created in the bucket once provisioned",
+		Values:      "object key",
+		parse: func(p *BucketParameters, key, value string) error {
+			p.PlaceholderObject = value
+			return nil
+		},
+	},
+	{
+		Key:         PlaceholderObjectContent,
+		Description: "content of the placeholder object, requires " + PlaceholderObject,
+		Values:      fmt.Sprintf("up to %d bytes", MaxPlaceholderObjectContent),
+		parse: func(p *BucketParameters, key, value string) error {
+			p.PlaceholderObjectContent = value
+			return nil
+		},
+	},
+}
+
+// lookupParameter returns the supported parameter the key belongs to
+func lookupParameter(key string) (ParameterInfo, bool) {
+	for _, p := range SupportedParameters {
+		if p.prefix == "" && key == p.Key {
+			return p, true
+		}
+		if p.prefix != "" && strings.HasPrefix(key, p.prefix) && key != p.prefix {
+			return p, true
+		}
+	}
+	return ParameterInfo{}, false
+}
+
+// IsSupportedParameter reports whether the key is
+// understood by ParseBucketParameters
+func IsSupportedParameter(key string) bool {
+	_, ok := lookupParameter(key)
+	return ok
+}
+
+// ParametersWithAliases returns SupportedParameters, listing for each
+// parameter the keys that the given aliases resolve to it
+func ParametersWithAliases(aliases map[string]string) []ParameterInfo {
+	params := make([]ParameterInfo, len(SupportedParameters))
+	for i, p := range SupportedParameters {
+		for alias, key := range aliases {
+			if key == p.Key {
+				p.Aliases = append(p.Aliases, alias)
+			}
+		}
+		sort.Strings(p.Aliases)
+		params[i] = p
+	}
+	return params
+}
+
+// BucketParameters are the typed bucket parameters of a create request
+type BucketParameters struct {
+	BucketPrefix string
+
+	ObjectLocking  bool
+	ObjectLockMode string
+	ObjectLockDays int
+
+	Versioning string
+	Encryption EncryptionConfig
+	Tags       map[string]string
+
+	LifecycleExpiryDays  int
+	NoncurrentExpireDays int
+
+	// Quota is the size limit of the bucket in bytes
+	Quota uint64
+
+	PlaceholderObject        string
+	PlaceholderObjectContent string
+}
+
+// ParseBucketParameters parses and validates bucket parameters, whose
+// keys must already be in canonical form. All invalid parameters,
+// including conflicting ones, are reported together, in a single error
+func ParseBucketParameters(parameters map[string]string) (BucketParameters, error) {
+	p := BucketParameters{
+		Tags: map[string]string{},
+	}
+	invalid := []string{}
+
+	// Keys are parsed in order, so that the
+	// error lists them in a stable order
+	keys := make([]string, 0, len(parameters))
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		param, ok := lookupParameter(k)
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("invalid parameter %s", k))
+			continue
+		}
+		if err := param.parse(&p, k, parameters[k]); err != nil {
+			invalid = append(invalid, err.Error())
+		}
+	}
+
+	invalid = append(invalid, parameterConflictErrors(parameters)...)
+
+	// The keys are checked rather than the values, so
+	// that zero values are not taken as unset
+	_, hasMode := parameters[ObjectLockMode]
+	_, hasDays := parameters[ObjectLockDays]
+	if hasMode || hasDays {
+		if !p.ObjectLocking {
+			invalid = append(invalid, fmt.Sprintf("%s and %s require %s", ObjectLockMode, ObjectLockDays, ObjectLocking))
+		} else if err := ValidateObjectLockRetention(p.ObjectLockMode, p.ObjectLockDays); err != nil {
+			invalid = append(invalid, fmt.Sprintf("invalid object lock retention: %v", err))
+		}
+	}
+
+	if err := ValidateBucketTags(p.Tags); err != nil {
+		invalid = append(invalid, fmt.Sprintf("invalid bucket tags: %v", err))
+	}
+
+	if p.Encryption != (EncryptionConfig{}) {
+		if err := p.Encryption.Validate(); err != nil {
+			invalid = append(invalid, fmt.Sprintf("invalid bucket encryption: %v", err))
+		}
+	}
+
+	if p.PlaceholderObjectContent != "" && p.PlaceholderObject == "" {
+		invalid = append(invalid, "placeholder object content requires a placeholder object key")
+	}
+	if len(p.PlaceholderObjectContent) > MaxPlaceholderObjectContent {
+		invalid = append(invalid, fmt.Sprintf("placeholder object content exceeds %d bytes", MaxPlaceholderObjectContent))
+	}
+
+	if len(invalid) > 0 {
+		return p, errors.New(strings.Join(invalid, "; "))
+	}
+	return p, nil
+}
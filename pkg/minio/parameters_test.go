@@ -0,0 +1,183 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSupportedParametersAreParsed(t *testing.T) {
+	for _, p := range SupportedParameters {
+		if p.parse == nil {
+			t.Errorf("supported parameter %s is not parsed", p.Key)
+			continue
+		}
+		_, err := ParseBucketParameters(map[string]string{p.Key: "1"})
+		if err != nil && strings.Contains(err.Error(), "invalid parameter "+p.Key) {
+			t.Errorf("supported parameter %s is not understood by the parser", p.Key)
+		}
+	}
+}
+
+func TestUnknownParametersAreRejected(t *testing.T) {
+	for _, k := range []string{"unknown", "Versioning", "objectlocking", TagPrefix} {
+		_, err := ParseBucketParameters(map[string]string{k: "1"})
+		if err == nil || !strings.Contains(err.Error(), "invalid parameter "+k) {
+			t.Errorf("expected parameter %s to be rejected, got %v", k, err)
+		}
+	}
+}
+
+// TestParameterConstantsAreSupported checks that the string constants
+// of const.go are supported parameters, or values of one
+func TestParameterConstantsAreSupported(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "const.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parsing const.go failed: %v", err)
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, value := range spec.Values {
+			lit, ok := value.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+			v, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatalf("unquoting %s failed: %v", lit.Value, err)
+			}
+			if !isParameterKeyOrValue(v) {
+				t.Errorf("constant %s (%q) is neither a supported parameter nor a value of one", spec.Names[i], v)
+			}
+		}
+		return false
+	})
+}
+
+func isParameterKeyOrValue(v string) bool {
+	if IsSupportedParameter(v) {
+		return true
+	}
+	for _, p := range SupportedParameters {
+		if v == p.prefix {
+			return true
+		}
+		for _, value := range strings.Fields(p.Values) {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestParameterAliasesAreSupported(t *testing.T) {
+	supported := map[string]bool{}
+	for _, p := range SupportedParameters {
+		supported[p.Key] = true
+	}
+	for alias, key := range ParameterAliases {
+		if !supported[key] {
+			t.Errorf("alias %s resolves to unsupported parameter %s", alias, key)
+		}
+	}
+}
+
+func TestParametersWithAliases(t *testing.T) {
+	params := ParametersWithAliases(map[string]string{
+		"objectLocking":  ObjectLocking,
+		"object-locking": ObjectLocking,
+	})
+
+	for _, p := range params {
+		if p.Key != ObjectLocking {
+			if len(p.Aliases) != 0 {
+				t.Errorf("expected no aliases for %s, got %v", p.Key, p.Aliases)
+			}
+			continue
+		}
+		if strings.Join(p.Aliases, ",") != "object-locking,objectLocking" {
+			t.Errorf("expected aliases object-locking,objectLocking for %s, got %v", p.Key, p.Aliases)
+		}
+	}
+}
+
+func TestObjectLockRetentionParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		wantErr    string
+	}{
+		{
+			name: "retention with object locking",
+			parameters: map[string]string{
+				ObjectLocking:  "true",
+				ObjectLockMode: "GOVERNANCE",
+				ObjectLockDays: "30",
+			},
+		},
+		{
+			name: "retention without object locking",
+			parameters: map[string]string{
+				ObjectLockMode: "GOVERNANCE",
+				ObjectLockDays: "30",
+			},
+			wantErr: "require " + ObjectLocking,
+		},
+		{
+			name:       "zero days without object locking",
+			parameters: map[string]string{ObjectLockDays: "0"},
+			wantErr:    "require " + ObjectLocking,
+		},
+		{
+			name:       "empty mode without object locking",
+			parameters: map[string]string{ObjectLockMode: ""},
+			wantErr:    "require " + ObjectLocking,
+		},
+		{
+			name: "zero days with object locking",
+			parameters: map[string]string{
+				ObjectLocking:  "true",
+				ObjectLockMode: "GOVERNANCE",
+				ObjectLockDays: "0",
+			},
+			wantErr: "invalid object lock retention",
+		},
+		{
+			name:       "object locking without retention",
+			parameters: map[string]string{ObjectLocking: "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBucketParameters(tt.parameters)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+)
+
+// quotaUnits are the multipliers of the size suffixes accepted for
+// quotas, binary like Gi as well as decimal like G
+var quotaUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"Pi", 1 << 50},
+	{"k", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+	{"P", 1e15},
+}
+
+// ParseQuota parses a bucket quota given as a number of bytes,
+// optionally with a size suffix, e.g. 5Gi. The quota must be positive
+func ParseQuota(s string) (uint64, error) {
+	number, multiplier := s, uint64(1)
+	for _, u := range quotaUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			number, multiplier = strings.TrimSuffix(s, u.suffix), u.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseUint(number, 10, 64)
+	if err != nil || n == 0 {
+		return 0, errors.Errorf("invalid quota %q, must be a positive size, e.g. 5Gi", s)
+	}
+	if n > ^uint64(0)/multiplier {
+		return 0, errors.Errorf("quota %q is too large", s)
+	}
+	return n * multiplier, nil
+}
+
+// SetBucketQuota sets a hard quota, in bytes, on the size of the
+// bucket. Setting it again replaces the previous quota
+func (x *C) SetBucketQuota(ctx context.Context, bucketName string, bytes uint64) error {
+	err := x.admin.SetBucketQuota(ctx, bucketName, &madmin.BucketQuota{
+		Quota: bytes,
+		Type:  madmin.HardQuota,
+	})
+	return adminError(err)
+}
+
+// adminError returns errors of the admin API as S3 errors, so
+// that they are handled like the errors of other requests
+func adminError(err error) error {
+	if errResp, ok := err.(madmin.ErrorResponse); ok {
+		return minio.ErrorResponse{
+			Code:       errResp.Code,
+			Message:    errResp.Message,
+			BucketName: errResp.BucketName,
+			RequestID:  errResp.RequestID,
+			HostID:     errResp.HostID,
+			Region:     errResp.Region,
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"testing"
+)
+
+func TestParseQuota(t *testing.T) {
+	tests := []struct {
+		quota   string
+		want    uint64
+		wantErr bool
+	}{
+		{quota: "1024", want: 1024},
+		{quota: "5Gi", want: 5 << 30},
+		{quota: "512Mi", want: 512 << 20},
+		{quota: "2T", want: 2e12},
+		{quota: "0", wantErr: true},
+		{quota: "0Gi", wantErr: true},
+		{quota: "-1Gi", wantErr: true},
+		{quota: "1.5Gi", wantErr: true},
+		{quota: "5GB", wantErr: true},
+		{quota: "", wantErr: true},
+		{quota: "100000Pi", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.quota, func(t *testing.T) {
+			got, err := ParseQuota(tt.quota)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d bytes, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/pkg/errors"
+)
+
+// S3 limits on the length of bucket tags
+const (
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+// ValidateBucketTags checks that the tags can be set on a bucket
+func ValidateBucketTags(t map[string]string) error {
+	for k, v := range t {
+		if k == "" {
+			return errors.New("tag key cannot be empty")
+		}
+		if utf8.RuneCountInString(k) > MaxTagKeyLength {
+			return errors.Errorf("tag key %q exceeds %d characters", k, MaxTagKeyLength)
+		}
+		if utf8.RuneCountInString(v) > MaxTagValueLength {
+			return errors.Errorf("value of tag %q exceeds %d characters", k, MaxTagValueLength)
+		}
+	}
+	if _, err := tags.MapToBucketTags(t); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetBucketTags replaces the tags of the bucket with the given tags
+func (x *C) SetBucketTags(ctx context.Context, bucketName string, t map[string]string) error {
+	bucketTags, err := tags.MapToBucketTags(t)
+	if err != nil {
+		return err
+	}
+	return x.client.SetBucketTagging(ctx, bucketName, bucketTags)
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	min "github.com/minio/minio-go/v7"
+)
+
+// CorrelationIDHeader is the request header carrying the
+// correlation ID of the operation a request to MinIO is part of
+const CorrelationIDHeader = "X-Correlation-Id"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context whose requests to
+// MinIO carry the given correlation ID
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of the
+// context, or an empty string if it has none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// tlsVersions maps the accepted values of
+// ClientOptions.MinTLSVersion to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Validate checks the settings that do not depend on the endpoint
+func (o ClientOptions) Validate() error {
+	if _, ok := tlsVersions[o.MinTLSVersion]; o.MinTLSVersion != "" && !ok {
+		return errors.Errorf("unsupported minimum TLS version %q", o.MinTLSVersion)
+	}
+	if o.MaxIdleConns < 0 {
+		return errors.New("max idle connections cannot be negative")
+	}
+	if o.MaxConnsPerHost < 0 {
+		return errors.New("max connections per host cannot be negative")
+	}
+	return nil
+}
+
+// newTransport builds the transport used for requests to MinIO
+func newTransport(secure bool, opts ClientOptions) (http.RoundTripper, error) {
+	if !secure && len(opts.CABundle) > 0 {
+		return nil, errors.New("CA bundle requires an https minio endpoint")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tr, err := min.DefaultTransport(secure)
+	if err != nil {
+		return nil, err
+	}
+
+	if secure {
+		minVersion := uint16(tls.VersionTLS12)
+		if opts.MinTLSVersion != "" {
+			minVersion = tlsVersions[opts.MinTLSVersion]
+		}
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.MinVersion = minVersion
+
+		if len(opts.CABundle) > 0 {
+			rootCAs := tr.TLSClientConfig.RootCAs
+			if rootCAs == nil {
+				rootCAs, err = x509.SystemCertPool()
+				if err != nil {
+					rootCAs = x509.NewCertPool()
+				}
+			}
+			if !rootCAs.AppendCertsFromPEM(opts.CABundle) {
+				return nil, errors.New("CA bundle contains no valid certificates")
+			}
+			tr.TLSClientConfig.RootCAs = rootCAs
+		}
+		tr.TLSClientConfig.InsecureSkipVerify = opts.InsecureSkipVerify
+	}
+
+	if opts.MaxIdleConns > 0 {
+		tr.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+
+	var rt http.RoundTripper = &correlationTransport{tr}
+	if opts.DisableRedirects {
+		rt = &noRedirectTransport{rt}
+	}
+	return rt, nil
+}
+
+// correlationTransport sets the correlation ID of the
+// request context, if any, as a request header
+type correlationTransport struct {
+	http.RoundTripper
+}
+
+func (t *correlationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := CorrelationID(req.Context()); id != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// noRedirectTransport fails requests that MinIO answers with
+// a redirect. Otherwise, the client follows the redirect, possibly
+// to an internal host that is not reachable from the driver
+type noRedirectTransport struct {
+	http.RoundTripper
+}
+
+// redirectNotFollowed is the S3 error code of redirects
+// that are not followed
+const redirectNotFollowed = "RedirectNotFollowed"
+
+func (t *noRedirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	// The redirect is turned into an S3 error response without a
+	// location, which the http client returns as is. Unlike transport
+	// errors, the MinIO client does not retry it
+	body, err := xml.Marshal(min.ErrorResponse{
+		Code:    redirectNotFollowed,
+		Message: fmt.Sprintf("minio redirected request to %s, but following redirects is disabled", location),
+	})
+	if err != nil {
+		return nil, err
+	}
+	header := resp.Header.Clone()
+	header.Del("Location")
+	header.Set("Content-Type", "application/xml")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
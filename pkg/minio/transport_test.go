@@ -0,0 +1,204 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	min "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func TestTransportTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	tests := []struct {
+		name    string
+		opts    ClientOptions
+		wantErr bool
+	}{
+		{
+			name: "trusted CA bundle",
+			opts: ClientOptions{CABundle: caBundle},
+		},
+		{
+			name:    "without CA bundle",
+			wantErr: true,
+		},
+		{
+			name: "minimum TLS version met",
+			opts: ClientOptions{CABundle: caBundle, MinTLSVersion: "1.2"},
+		},
+		{
+			name:    "minimum TLS version not met",
+			opts:    ClientOptions{CABundle: caBundle, MinTLSVersion: "1.3"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, err := newTransport(true, tt.opts)
+			if err != nil {
+				t.Fatalf("building transport failed: %v", err)
+			}
+			resp, err := (&http.Client{Transport: rt}).Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTransportMinTLSVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+	}{
+		{version: "", want: tls.VersionTLS12},
+		{version: "1.2", want: tls.VersionTLS12},
+		{version: "1.3", want: tls.VersionTLS13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			rt, err := newTransport(true, ClientOptions{MinTLSVersion: tt.version})
+			if err != nil {
+				t.Fatalf("building transport failed: %v", err)
+			}
+			tr := rt.(*correlationTransport).RoundTripper.(*http.Transport)
+			if got := tr.TLSClientConfig.MinVersion; got != tt.want {
+				t.Errorf("expected minimum TLS version %x, got %x", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransportConnectionLimits(t *testing.T) {
+	rt, err := newTransport(false, ClientOptions{MaxIdleConns: 10, MaxConnsPerHost: 5})
+	if err != nil {
+		t.Fatalf("building transport failed: %v", err)
+	}
+	tr := rt.(*correlationTransport).RoundTripper.(*http.Transport)
+	if tr.MaxIdleConns != 10 {
+		t.Errorf("expected %d max idle connections, got %d", 10, tr.MaxIdleConns)
+	}
+	if tr.MaxConnsPerHost != 5 {
+		t.Errorf("expected %d max connections per host, got %d", 5, tr.MaxConnsPerHost)
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		secure  bool
+		opts    ClientOptions
+		wantErr string
+	}{
+		{
+			name:   "https",
+			secure: true,
+			opts:   ClientOptions{MinTLSVersion: "1.3"},
+		},
+		{
+			name:    "unsupported minimum TLS version",
+			secure:  true,
+			opts:    ClientOptions{MinTLSVersion: "1.1"},
+			wantErr: "unsupported minimum TLS version",
+		},
+		{
+			name:    "invalid CA bundle",
+			secure:  true,
+			opts:    ClientOptions{CABundle: []byte("not a certificate")},
+			wantErr: "no valid certificates",
+		},
+		{
+			name:    "negative connection limit",
+			opts:    ClientOptions{MaxConnsPerHost: -1},
+			wantErr: "cannot be negative",
+		},
+		{
+			name:    "CA bundle with http",
+			opts:    ClientOptions{CABundle: []byte("not a certificate")},
+			wantErr: "requires an https minio endpoint",
+		},
+		{
+			name: "http",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTransport(tt.secure, tt.opts)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNoRedirectTransport(t *testing.T) {
+	var requests, redirected int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			atomic.AddInt32(&redirected, 1)
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	rt, err := newTransport(false, ClientOptions{DisableRedirects: true})
+	if err != nil {
+		t.Fatalf("building transport failed: %v", err)
+	}
+	client, err := min.New(strings.TrimPrefix(srv.URL, "http://"), &min.Options{
+		Creds:     credentials.NewStaticV4("access", "secret", ""),
+		Transport: rt,
+		Region:    "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("creating client failed: %v", err)
+	}
+
+	err = client.MakeBucket(context.Background(), "bucket", min.MakeBucketOptions{})
+	if code := min.ToErrorResponse(err).Code; code != redirectNotFollowed {
+		t.Fatalf("expected error code %s, got %v", redirectNotFollowed, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected the redirect not to be retried, got %d requests", n)
+	}
+	if n := atomic.LoadInt32(&redirected); n != 0 {
+		t.Errorf("expected the redirect not to be followed, got %d requests", n)
+	}
+}
@@ -15,10 +15,19 @@ package pkg
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"k8s.io/klog/v2"
 
 	cosi "sigs.k8s.io/container-object-storage-interface-spec"
@@ -26,9 +35,88 @@ import (
 	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
 )
 
+// defaultSignatureVersion is the signature version MinIO
+// clients use when none is requested
+const defaultSignatureVersion = cosi.S3SignatureVersion_S3V4
+
+// optionsRetryDelay is the delay recommended to the sidecar
+// before retrying a create whose options are still pending
+const optionsRetryDelay = 5 * time.Second
+
+// removeBucketTimeout bounds the removal of a bucket whose options
+// failed to apply, which may run after the request context is done
+const removeBucketTimeout = 30 * time.Second
+
 type ProvisionerServer struct {
 	provisioner string
-	mc          *minio.C
+	mc          minio.BucketClient
+
+	parameterAliases         map[string]string
+	allowedSignatureVersions map[cosi.S3SignatureVersion]bool
+	validateRequestEndpoint  bool
+	bestEffortOptions        bool
+	adoptExisting            bool
+	forceDelete              bool
+	defaultEncryption        minio.EncryptionConfig
+	maxAccessPolicySize      int
+	retry                    RetryPolicy
+
+	createInflight *inflight
+	metrics        *metrics
+}
+
+// signatureVersionAllowed reports whether consumers may request the
+// given signature version. Requests that leave it unset get the
+// default, which is checked like a requested version. Without an
+// allowed list, only the default is allowed
+func (s *ProvisionerServer) signatureVersionAllowed(v cosi.S3SignatureVersion) bool {
+	if v == cosi.S3SignatureVersion_UnknownSignature {
+		v = defaultSignatureVersion
+	}
+	if len(s.allowedSignatureVersions) == 0 {
+		return v == defaultSignatureVersion
+	}
+	return s.allowedSignatureVersions[v]
+}
+
+// resolveParameter returns the key understood by the driver
+// for the given parameter key, following any configured alias
+func (s *ProvisionerServer) resolveParameter(key string) string {
+	if k, ok := s.parameterAliases[key]; ok {
+		return k
+	}
+	return key
+}
+
+// resolveParameters returns the parameters keyed by the keys understood
+// by the driver. Keys resolving to the same key, such as a key and its
+// alias, are rejected, since only one of their values could apply
+func (s *ProvisionerServer) resolveParameters(parameters map[string]string) (map[string]string, error) {
+	// Keys are resolved in order, so that the
+	// error names them in a stable order
+	keys := make([]string, 0, len(parameters))
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	resolved := map[string]string{}
+	resolvedFrom := map[string]string{}
+	duplicates := []string{}
+	for _, k := range keys {
+		key := s.resolveParameter(k)
+		if other, ok := resolvedFrom[key]; ok {
+			duplicates = append(duplicates, fmt.Sprintf("parameters %s and %s both set %s", other, k, key))
+			continue
+		}
+		resolvedFrom[key] = k
+		resolved[key] = parameters[k]
+	}
+
+	if len(duplicates) > 0 {
+		return nil, errors.New(strings.Join(duplicates, "; "))
+	}
+	return resolved, nil
 }
 
 // ProvisionerCreateBucket is an idempotent method for creating buckets
@@ -38,7 +126,41 @@ type ProvisionerServer struct {
 //    nil -                   Bucket successfully created
 //    codes.AlreadyExists -   Bucket already exists. No more retries
 //    non-nil err -           Internal error                                [requeue'd with exponential backoff]
+// gRPC errors carry no response, so codes.AlreadyExists comes without the
+// BucketId, which is the name of the existing bucket
+// When existing buckets are adopted, an existing bucket has its options
+// reconciled with the request, and nil is returned instead
+// A request arriving while an identical one is still in progress, or
+// within the configured grace window after it succeeded or found the
+// bucket existing, returns the same result
+// The S3 endpoint of the protocol never selects the target of the request.
+// When request endpoint validation is enabled, an endpoint other than the
+// driver's returns codes.InvalidArgument, otherwise it is ignored
 func (s *ProvisionerServer) ProvisionerCreateBucket(ctx context.Context,
+	req *cosi.ProvisionerCreateBucketRequest) (_ *cosi.ProvisionerCreateBucketResponse, err error) {
+	defer s.metrics.observe(operationCreate, time.Now(), &err)
+
+	key, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		klog.ErrorS(err, "Encoding request failed")
+		return nil, status.Error(codes.Internal, "Bucket creation failed")
+	}
+
+	resp, err := s.createInflight.Do(ctx, string(key), func() (interface{}, error) {
+		// The correlation ID ties the logs of this operation to
+		// the requests it sends to MinIO
+		id := uuid.New().String()
+		resp, err := s.createBucket(minio.WithCorrelationID(ctx, id), req)
+		klog.V(3).InfoS("Create Bucket finished", "correlationID", id, "code", status.Code(err))
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*cosi.ProvisionerCreateBucketResponse), nil
+}
+
+func (s *ProvisionerServer) createBucket(ctx context.Context,
 	req *cosi.ProvisionerCreateBucketRequest) (*cosi.ProvisionerCreateBucketResponse, error) {
 
 	protocol := req.GetProtocol()
@@ -53,7 +175,11 @@ func (s *ProvisionerServer) ProvisionerCreateBucket(ctx context.Context,
 	}
 
 	bucketName := s3.BucketName
-	klog.V(3).InfoS("Create Bucket", "name", bucketName)
+	if bucketName == "" {
+		klog.ErrorS(errors.New("Invalid Argument"), "Bucket name is empty")
+		return nil, status.Error(codes.InvalidArgument, "Bucket name is empty")
+	}
+	klog.V(3).InfoS("Create Bucket", "name", bucketName, "correlationID", minio.CorrelationID(ctx))
 
 	options := minio.MakeBucketOptions{}
 
@@ -62,40 +188,250 @@ func (s *ProvisionerServer) ProvisionerCreateBucket(ctx context.Context,
 	// is needed here
 	options.Region = s3.Region
 
-	// Support for the following two fields will be added
-	// in the future using which bucket will be provisioned in a
-	// particular region conforming to a particular signature version
-	// However, as of now, these will be ignored
+	// The endpoint is ignored unless request endpoint validation
+	// is enabled, in which case it must match the endpoint of
+	// the driver
+	if s.validateRequestEndpoint && s3.Endpoint != "" && !s.mc.MatchesEndpoint(s3.Endpoint) {
+		klog.ErrorS(errors.New("Invalid Argument"), "Endpoint does not match driver endpoint", "endpoint", s3.Endpoint)
+		return nil, status.Error(codes.InvalidArgument, "endpoint does not match driver endpoint")
+	}
 
-	// endpoint := s3.Endpoint
-	// signatureVersion := s3.SignatureVersion
+	// The signature version is not used for provisioning, but
+	// consumers may only request the versions operators allow
+	if !s.signatureVersionAllowed(s3.SignatureVersion) {
+		klog.ErrorS(errors.New("Invalid Argument"), "Signature version not allowed", "signatureVersion", s3.SignatureVersion)
+		return nil, status.Error(codes.InvalidArgument, "signature version not allowed")
+	}
 
 	// Since 'parameters' is not a typed construct
 	// it is better to have predefined set of keys
 	// to parse, rather than treating it as an opaque
-	// set of keys and values.
-	parameters := req.GetParameters()
-
-	for k, v := range parameters {
-		switch k {
-		case minio.ObjectLocking:
-			options.ObjectLocking = true
-		default:
-			klog.ErrorS(errors.New("Invalid Argument"), "parameter", k, "value", v)
-			return nil, status.Error(codes.InvalidArgument, "invalid parameter")
-		}
+	// set of keys and values. Aliased keys are resolved
+	// to their canonical form before being parsed.
+	resolved, err := s.resolveParameters(req.GetParameters())
+	if err != nil {
+		klog.ErrorS(err, "Invalid parameters")
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parameters: %v", err)
 	}
 
-	bucketID, err := s.mc.CreateBucket(ctx, bucketName, options)
+	params, err := minio.ParseBucketParameters(resolved)
 	if err != nil {
+		klog.ErrorS(err, "Invalid parameters")
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parameters: %v", err)
+	}
+
+	// The prefix is part of the bucket name, so the bucket ID
+	// refers to the prefixed bucket. The name is validated with
+	// the prefix applied, since both count towards its limits
+	bucketName = params.BucketPrefix + bucketName
+	if err := minio.ValidateBucketName(bucketName); err != nil {
+		klog.ErrorS(err, "Invalid bucket name", "name", bucketName)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid bucket name %q: %v", bucketName, err)
+	}
+
+	options.ObjectLocking = params.ObjectLocking
+
+	// The default encryption applies to requests that set none
+	encryption := params.Encryption
+	if encryption == (minio.EncryptionConfig{}) {
+		encryption = s.defaultEncryption
+	}
+
+	var bucketID string
+	// An attempt whose response was lost may have created the
+	// bucket on the server, in which case the retry finds it owned
+	// by the driver account. It may also have existed before, so
+	// such a bucket is not taken as created by this call
+	lostResponse, foundAfterLostResponse := false, false
+	err = s.retry.do(ctx, func() (err error) {
+		bucketID, err = s.mc.CreateBucket(ctx, bucketName, options)
+		if err == minio.ErrBucketAlreadyOwnedByYou && lostResponse {
+			klog.InfoS("Bucket found after a lost create response, not reporting it as existing", "name", bucketName)
+			foundAfterLostResponse = true
+			return nil
+		}
+		lostResponse = lostResponse || minio.IsTransportError(err)
+		return err
+	})
+	if err != nil {
+		if minio.IsRequestTimeTooSkewed(err) {
+			return nil, clockSkewError(err)
+		}
 		if err == minio.ErrBucketAlreadyExists {
+			// Buckets of other accounts are never adopted
+			klog.InfoS("Bucket already exists and is owned by another account", "name", bucketName)
+			return nil, status.Error(codes.AlreadyExists, "Bucket already exists and is owned by another account")
+		}
+		if err != minio.ErrBucketAlreadyOwnedByYou {
+			klog.ErrorS(err, "Bucket creation failed")
+			return nil, status.Error(codes.Internal, "Bucket creation failed")
+		}
+		if !s.adoptExisting {
 			klog.InfoS("Bucket already exists", "name", bucketName)
-			return &cosi.ProvisionerCreateBucketResponse{
-				BucketId: bucketID,
-			}, nil
+			return nil, status.Error(codes.AlreadyExists, "Bucket already exists")
 		}
-		klog.ErrorS(err, "Bucket creation failed")
-		return nil, status.Error(codes.Internal, "Bucket creation failed")
+		// The existing bucket is adopted, so the rest of the
+		// provisioning reconciles its options with the request
+		klog.InfoS("Bucket already exists, adopting it", "name", bucketName)
+	}
+	created := err == nil && !foundAfterLostResponse
+
+	// Options are applied once the bucket exists. Each returns
+	// the gRPC error to fail the create with, and records the
+	// effective values of the parameters it applied
+	bucketOptions := []func() error{}
+	applied := map[string]string{}
+
+	// Encryption is set first, so that objects written
+	// to the bucket are encrypted from the start
+	if encryption.Type != "" {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetBucketEncryption(ctx, bucketName, encryption)
+			})
+			if err != nil {
+				return optionError(err, "Setting bucket encryption failed", "bucket", bucketName, "encryption", encryption.Type)
+			}
+			applied[minio.Encryption] = encryption.Type
+			if encryption.KMSKeyID != "" {
+				applied[minio.KMSKeyID] = encryption.KMSKeyID
+			}
+			return nil
+		})
+	}
+
+	if params.ObjectLockMode != "" {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetObjectLockConfig(ctx, bucketName, params.ObjectLockMode, params.ObjectLockDays)
+			})
+			if err != nil {
+				return optionError(err, "Setting object lock retention failed", "bucket", bucketName, "mode", params.ObjectLockMode, "days", params.ObjectLockDays)
+			}
+			applied[minio.ObjectLockMode] = params.ObjectLockMode
+			applied[minio.ObjectLockDays] = strconv.Itoa(params.ObjectLockDays)
+			return nil
+		})
+	}
+
+	// Tags replace the existing tags, so that adopted
+	// buckets follow changes to the parameters
+	if len(params.Tags) > 0 {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetBucketTags(ctx, bucketName, params.Tags)
+			})
+			if err != nil {
+				return optionError(err, "Setting bucket tags failed", "bucket", bucketName)
+			}
+			for k, v := range params.Tags {
+				applied[minio.TagPrefix+k] = v
+			}
+			return nil
+		})
+	}
+
+	// Versioning is set ahead of the options that depend on it
+	if params.Versioning != "" {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetBucketVersioning(ctx, bucketName, params.Versioning == minio.VersioningEnabled)
+			})
+			if err != nil {
+				return optionError(err, "Setting bucket versioning failed", "bucket", bucketName, "versioning", params.Versioning)
+			}
+			applied[minio.Versioning] = params.Versioning
+			return nil
+		})
+	}
+
+	// Zero days removes the expiry rule of adopted
+	// buckets, so that they follow the parameters
+	_, expirySet := resolved[minio.LifecycleExpiryDays]
+	if params.LifecycleExpiryDays > 0 || (expirySet && !created) {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetBucketLifecycle(ctx, bucketName, params.LifecycleExpiryDays)
+			})
+			if err != nil {
+				return optionError(err, "Setting lifecycle expiration failed", "bucket", bucketName)
+			}
+			applied[minio.LifecycleExpiryDays] = strconv.Itoa(params.LifecycleExpiryDays)
+			return nil
+		})
+	}
+
+	if params.NoncurrentExpireDays > 0 {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetNoncurrentVersionExpiry(ctx, bucketName, params.NoncurrentExpireDays)
+			})
+			if err != nil {
+				// Versioning is checked once it is set, so that adopted
+				// buckets that are versioned already need no parameter
+				if err == minio.ErrVersioningNotEnabled {
+					klog.ErrorS(err, "Noncurrent version expiration requires versioning", "bucket", bucketName)
+					return status.Error(codes.FailedPrecondition, "noncurrent version expiration requires a versioned bucket")
+				}
+				return optionError(err, "Setting noncurrent version expiration failed", "bucket", bucketName)
+			}
+			applied[minio.NoncurrentExpireDays] = strconv.Itoa(params.NoncurrentExpireDays)
+			return nil
+		})
+	}
+
+	// The quota replaces any existing one, so that adopted
+	// buckets follow changes to the parameters
+	if params.Quota > 0 {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.mc.SetBucketQuota(ctx, bucketName, params.Quota)
+			})
+			if err != nil {
+				return optionError(err, "Setting bucket quota failed", "bucket", bucketName, "bytes", params.Quota)
+			}
+			applied[minio.Quota] = strconv.FormatUint(params.Quota, 10)
+			return nil
+		})
+	}
+
+	// The placeholder object is written last, once the
+	// bucket is configured
+	if params.PlaceholderObject != "" {
+		bucketOptions = append(bucketOptions, func() error {
+			err := s.retry.do(ctx, func() error {
+				return s.putPlaceholder(ctx, bucketName, params.PlaceholderObject, params.PlaceholderObjectContent)
+			})
+			if err != nil {
+				return optionError(err, "Creating placeholder object failed", "bucket", bucketName, "object", params.PlaceholderObject)
+			}
+			applied[minio.PlaceholderObject] = params.PlaceholderObject
+			return nil
+		})
+	}
+
+	// In best-effort mode, options that fail to apply are
+	// reported as warnings, and the create still succeeds
+	warnings := []string{}
+	for _, apply := range bucketOptions {
+		if err := apply(); err != nil {
+			if !s.bestEffortOptions {
+				// A bucket this call created is removed, so that the
+				// retry creates it again rather than finding it with
+				// options missing, e.g. unencrypted
+				if created {
+					s.removeIncompleteBucket(ctx, bucketName)
+				}
+				return nil, err
+			}
+			warnings = append(warnings, status.Convert(err).Message())
+		}
+	}
+	if len(warnings) > 0 {
+		klog.InfoS("Bucket created without all options applied", "name", bucketName, "warnings", warnings)
+	}
+	if len(applied) > 0 {
+		klog.V(3).InfoS("Bucket options applied", "name", bucketName, "options", applied)
 	}
 
 	return &cosi.ProvisionerCreateBucketResponse{
@@ -103,14 +439,143 @@ func (s *ProvisionerServer) ProvisionerCreateBucket(ctx context.Context,
 	}, nil
 }
 
+// optionError logs the failure to apply a bucket option,
+// and returns the gRPC error to fail the create with
+func optionError(err error, msg string, keysAndValues ...interface{}) error {
+	if minio.IsRequestTimeTooSkewed(err) {
+		return clockSkewError(err)
+	}
+	klog.ErrorS(err, msg, keysAndValues...)
+	return optionsPendingError(msg)
+}
+
+// clockSkewError returns a retryable error for requests MinIO rejected
+// due to clock skew, which is resolved by synchronizing the clocks
+// rather than by anything in the request
+func clockSkewError(err error) error {
+	klog.ErrorS(err, "Request rejected by MinIO due to clock skew, ensure the clocks of the driver and MinIO are synchronized")
+	return status.Error(codes.Unavailable, "request time too skewed from MinIO server time, check clock synchronization")
+}
+
+// optionsPendingError returns an Internal error for a create whose
+// options could not all be applied. It recommends a short retry delay,
+// since the retry either creates the bucket again, after the failed
+// one was removed, or only has to apply the options to an adopted bucket
+func optionsPendingError(msg string) error {
+	st := status.New(codes.Internal, msg)
+	ds, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(optionsRetryDelay),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
+// removeIncompleteBucket deletes a bucket whose options failed to apply.
+// Failures are only logged, since the create is failing already
+func (s *ProvisionerServer) removeIncompleteBucket(ctx context.Context, bucketName string) {
+	// The removal runs even if the request context is done,
+	// since that may be why the options failed
+	removeCtx, cancel := context.WithTimeout(minio.WithCorrelationID(context.Background(), minio.CorrelationID(ctx)), removeBucketTimeout)
+	defer cancel()
+
+	err := s.retry.do(removeCtx, func() error {
+		return s.mc.DeleteBucket(removeCtx, bucketName)
+	})
+	if err != nil && err != minio.ErrBucketNotFound {
+		klog.ErrorS(err, "Removing bucket with pending options failed, it must be removed or adopted to complete provisioning", "name", bucketName)
+		return
+	}
+	klog.InfoS("Removed bucket with pending options", "name", bucketName)
+}
+
+// putPlaceholder creates the placeholder object, unless it is already
+// present, so that retried creates do not overwrite it
+func (s *ProvisionerServer) putPlaceholder(ctx context.Context, bucketName, objectName, content string) error {
+	exists, err := s.mc.ObjectExists(ctx, bucketName, objectName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		klog.V(3).InfoS("Placeholder object already exists", "bucket", bucketName, "object", objectName)
+		return nil
+	}
+	return s.mc.PutObject(ctx, bucketName, objectName, []byte(content))
+}
+
 func (s *ProvisionerServer) ProvisionerDeleteBucket(ctx context.Context,
-	req *cosi.ProvisionerDeleteBucketRequest) (*cosi.ProvisionerDeleteBucketResponse, error) {
+	req *cosi.ProvisionerDeleteBucketRequest) (_ *cosi.ProvisionerDeleteBucketResponse, err error) {
+	defer s.metrics.observe(operationDelete, time.Now(), &err)
+
+	if req.GetBucketId() == "" {
+		klog.ErrorS(errors.New("Invalid Argument"), "BucketId is empty")
+		return nil, status.Error(codes.InvalidArgument, "BucketId is empty")
+	}
+
+	// The bucket ID is the bucket name
+	bucketName := req.GetBucketId()
+	klog.V(3).InfoS("Delete Bucket", "name", bucketName)
+
+	if s.forceDelete {
+		err := s.retry.do(ctx, func() error {
+			return s.mc.EmptyBucket(ctx, bucketName)
+		})
+		if err != nil {
+			if err == minio.ErrBucketNotFound {
+				klog.InfoS("Bucket does not exist", "name", bucketName)
+				return &cosi.ProvisionerDeleteBucketResponse{}, nil
+			}
+			if err == minio.ErrObjectUnderLegalHold {
+				klog.ErrorS(err, "Bucket holds objects under legal hold", "name", bucketName)
+				return nil, status.Error(codes.FailedPrecondition, "Bucket holds objects under legal hold, which must be released before deletion")
+			}
+			if minio.IsRequestTimeTooSkewed(err) {
+				return nil, clockSkewError(err)
+			}
+			klog.ErrorS(err, "Emptying bucket failed", "name", bucketName)
+			return nil, status.Error(codes.Internal, "Emptying bucket failed")
+		}
+	}
+
+	err = s.retry.do(ctx, func() error {
+		return s.mc.DeleteBucket(ctx, bucketName)
+	})
+	if err != nil {
+		switch {
+		case err == minio.ErrBucketNotFound:
+			// Deleting a bucket that is already gone succeeds,
+			// so that retried deletes are idempotent
+			klog.InfoS("Bucket does not exist", "name", bucketName)
+			return &cosi.ProvisionerDeleteBucketResponse{}, nil
+		case err == minio.ErrBucketNotEmpty:
+			klog.ErrorS(err, "Bucket is not empty", "name", bucketName)
+			return nil, status.Error(codes.FailedPrecondition, "Bucket is not empty")
+		case minio.IsRequestTimeTooSkewed(err):
+			return nil, clockSkewError(err)
+		}
+		klog.ErrorS(err, "Bucket deletion failed", "name", bucketName)
+		return nil, status.Error(codes.Internal, "Bucket deletion failed")
+	}
 
 	return &cosi.ProvisionerDeleteBucketResponse{}, nil
 }
 
 func (s *ProvisionerServer) ProvisionerGrantBucketAccess(ctx context.Context,
-	req *cosi.ProvisionerGrantBucketAccessRequest) (*cosi.ProvisionerGrantBucketAccessResponse, error) {
+	req *cosi.ProvisionerGrantBucketAccessRequest) (_ *cosi.ProvisionerGrantBucketAccessResponse, err error) {
+	defer s.metrics.observe(operationGrant, time.Now(), &err)
+
+	if req.GetBucketId() == "" {
+		klog.ErrorS(errors.New("Invalid Argument"), "BucketId is empty")
+		return nil, status.Error(codes.InvalidArgument, "BucketId is empty")
+	}
+
+	// The size is checked ahead of parsing, so that oversized
+	// policies cannot tie up the driver
+	if s.maxAccessPolicySize > 0 && len(req.GetAccessPolicy()) > s.maxAccessPolicySize {
+		klog.ErrorS(errors.New("Invalid Argument"), "Access policy too large", "size", len(req.GetAccessPolicy()), "max", s.maxAccessPolicySize)
+		return nil, status.Errorf(codes.InvalidArgument, "access policy exceeds %d bytes", s.maxAccessPolicySize)
+	}
 
 	return &cosi.ProvisionerGrantBucketAccessResponse{
 		AccountId:               "minio",
@@ -119,7 +584,8 @@ func (s *ProvisionerServer) ProvisionerGrantBucketAccess(ctx context.Context,
 }
 
 func (s *ProvisionerServer) ProvisionerRevokeBucketAccess(ctx context.Context,
-	req *cosi.ProvisionerRevokeBucketAccessRequest) (*cosi.ProvisionerRevokeBucketAccessResponse, error) {
+	req *cosi.ProvisionerRevokeBucketAccessRequest) (_ *cosi.ProvisionerRevokeBucketAccessResponse, err error) {
+	defer s.metrics.observe(operationRevoke, time.Now(), &err)
 
 	return &cosi.ProvisionerRevokeBucketAccessResponse{}, nil
 }
@@ -0,0 +1,1408 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	min "github.com/minio/minio-go/v7"
+
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio/fake"
+)
+
+const testEndpoint = "http://minio:9000"
+
+// newTestServer returns a provisioner backed by a fake client
+func newTestServer(t *testing.T, opts Options) (*ProvisionerServer, *fake.Client) {
+	t.Helper()
+
+	fc := fake.NewClient(testEndpoint)
+	s, err := NewProvisionerServer("minio.objectstorage.k8s.io", fc, opts)
+	if err != nil {
+		t.Fatalf("creating provisioner failed: %v", err)
+	}
+	return s, fc
+}
+
+func createRequest(bucketName string, parameters map[string]string) *cosi.ProvisionerCreateBucketRequest {
+	return &cosi.ProvisionerCreateBucketRequest{
+		Protocol: &cosi.Protocol{
+			Type: &cosi.Protocol_S3{
+				S3: &cosi.S3{
+					BucketName: bucketName,
+				},
+			},
+		},
+		Parameters: parameters,
+	}
+}
+
+// newS3Server returns a server answering requests the way MinIO does
+// for an account without buckets, calling observe, if not nil, before
+// answering each request
+func newS3Server(t *testing.T, observe func(r *http.Request)) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if observe != nil {
+			observe(r)
+		}
+		_, location := r.URL.Query()["location"]
+		switch {
+		case r.Method == http.MethodGet && location:
+			io.WriteString(w, "<LocationConstraint>us-east-1</LocationConstraint>")
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCreateBucketConcurrent(t *testing.T) {
+	s, fc := newTestServer(t, Options{
+		CreateGraceWindow: time.Minute,
+	})
+	req := createRequest("bucket", nil)
+
+	var wg sync.WaitGroup
+	resps := make([]*cosi.ProvisionerCreateBucketResponse, 2)
+	errs := make([]error, 2)
+	for i := range resps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resps[i], errs[i] = s.ProvisionerCreateBucket(context.Background(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range resps {
+		if status.Code(errs[i]) != codes.OK {
+			t.Fatalf("create %d: expected code %s, got %s", i, codes.OK, status.Code(errs[i]))
+		}
+		if resps[i].GetBucketId() != "bucket" {
+			t.Errorf("create %d: expected bucket ID %q, got %q", i, "bucket", resps[i].GetBucketId())
+		}
+	}
+	if len(fc.Buckets) != 1 {
+		t.Errorf("expected 1 bucket, got %d", len(fc.Buckets))
+	}
+}
+
+func TestCreateBucket(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		existing    bool
+		emptyName   bool
+		errors      map[string]error
+		parameters  map[string]string
+		wantCode    codes.Code
+		wantBucket  bool
+		wantNoCalls bool
+	}{
+		{
+			name:       "new bucket",
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name: "new bucket with options",
+			parameters: map[string]string{
+				minio.Versioning:           minio.VersioningEnabled,
+				minio.NoncurrentExpireDays: "7",
+				minio.TagPrefix + "team":   "platform",
+				minio.PlaceholderObject:    ".keep",
+			},
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name:       "owned by the driver account",
+			existing:   true,
+			wantCode:   codes.AlreadyExists,
+			wantBucket: true,
+		},
+		{
+			name:       "owned by the driver account, adopted",
+			opts:       Options{AdoptExisting: true},
+			existing:   true,
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name:     "owned by another account",
+			opts:     Options{AdoptExisting: true},
+			errors:   map[string]error{"CreateBucket": minio.ErrBucketAlreadyExists},
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "creation fails",
+			errors:   map[string]error{"CreateBucket": errors.New("boom")},
+			wantCode: codes.Internal,
+		},
+		{
+			name:        "empty bucket name",
+			emptyName:   true,
+			wantCode:    codes.InvalidArgument,
+			wantNoCalls: true,
+		},
+		{
+			name:        "invalid parameters",
+			parameters:  map[string]string{"unknown": "1", minio.Versioning: "on"},
+			wantCode:    codes.InvalidArgument,
+			wantNoCalls: true,
+		},
+		{
+			name:       "noncurrent expiry without versioning",
+			parameters: map[string]string{minio.NoncurrentExpireDays: "7"},
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:       "object locking with suspended versioning",
+			parameters: map[string]string{minio.ObjectLocking: "true", minio.Versioning: minio.VersioningSuspended},
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			name:       "noncurrent expiry with suspended versioning",
+			parameters: map[string]string{minio.NoncurrentExpireDays: "7", minio.Versioning: minio.VersioningSuspended},
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			name:       "quota not positive",
+			parameters: map[string]string{minio.Quota: "0"},
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			name:       "quota negative",
+			parameters: map[string]string{minio.Quota: "-5Gi"},
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			name:       "option fails on a new bucket",
+			parameters: map[string]string{minio.TagPrefix + "team": "platform"},
+			errors:     map[string]error{"SetBucketTags": errors.New("boom")},
+			wantCode:   codes.Internal,
+		},
+		{
+			name:       "option fails on an adopted bucket",
+			opts:       Options{AdoptExisting: true},
+			existing:   true,
+			parameters: map[string]string{minio.TagPrefix + "team": "platform"},
+			errors:     map[string]error{"SetBucketTags": errors.New("boom")},
+			wantCode:   codes.Internal,
+			wantBucket: true,
+		},
+		{
+			name:       "option fails in best-effort mode",
+			opts:       Options{BestEffortOptions: true},
+			parameters: map[string]string{minio.TagPrefix + "team": "platform"},
+			errors:     map[string]error{"SetBucketTags": errors.New("boom")},
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, tt.opts)
+			if tt.existing {
+				fc.Buckets["bucket"] = &fake.Bucket{Objects: map[string][]byte{}}
+			}
+			for op, err := range tt.errors {
+				fc.Errors[op] = err
+			}
+
+			bucketName := "bucket"
+			if tt.emptyName {
+				bucketName = ""
+			}
+
+			resp, err := s.ProvisionerCreateBucket(context.Background(), createRequest(bucketName, tt.parameters))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && resp.GetBucketId() != "bucket" {
+				t.Errorf("expected bucket ID %q, got %q", "bucket", resp.GetBucketId())
+			}
+			if _, ok := fc.Buckets["bucket"]; ok != tt.wantBucket {
+				t.Errorf("expected bucket to exist %v, got %v", tt.wantBucket, ok)
+			}
+			if tt.wantNoCalls && len(fc.Calls) > 0 {
+				t.Errorf("expected no calls to MinIO, got %v", fc.Calls)
+			}
+		})
+	}
+}
+
+func TestCreateBucketNoncurrentExpiry(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   *fake.Bucket
+		parameters map[string]string
+		wantCode   codes.Code
+		wantBucket bool
+	}{
+		{
+			name: "new bucket with versioning",
+			parameters: map[string]string{
+				minio.Versioning:           minio.VersioningEnabled,
+				minio.NoncurrentExpireDays: "7",
+			},
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name:       "new bucket without versioning",
+			parameters: map[string]string{minio.NoncurrentExpireDays: "7"},
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:       "adopted versioned bucket",
+			existing:   &fake.Bucket{Versioning: true, Objects: map[string][]byte{}},
+			parameters: map[string]string{minio.NoncurrentExpireDays: "7"},
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name:       "adopted bucket without versioning",
+			existing:   &fake.Bucket{Objects: map[string][]byte{}},
+			parameters: map[string]string{minio.NoncurrentExpireDays: "7"},
+			wantCode:   codes.FailedPrecondition,
+			wantBucket: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, Options{AdoptExisting: true})
+			if tt.existing != nil {
+				fc.Buckets["bucket"] = tt.existing
+			}
+
+			_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", tt.parameters))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			b, ok := fc.Buckets["bucket"]
+			if ok != tt.wantBucket {
+				t.Fatalf("expected bucket to exist %v, got %v", tt.wantBucket, ok)
+			}
+			if tt.wantCode == codes.OK && b.NoncurrentExpiryDays != 7 {
+				t.Errorf("expected noncurrent expiry of 7 days, got %d", b.NoncurrentExpiryDays)
+			}
+		})
+	}
+}
+
+func TestCreateBucketInvalidParameters(t *testing.T) {
+	s, fc := newTestServer(t, Options{})
+	parameters := map[string]string{
+		minio.Versioning:          "sometimes",
+		minio.LifecycleExpiryDays: "-1",
+		minio.Quota:               "0",
+		"color":                   "blue",
+	}
+
+	_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", parameters))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected code %s, got %v", codes.InvalidArgument, err)
+	}
+	// All invalid parameters are reported at once
+	message := status.Convert(err).Message()
+	for key := range parameters {
+		if !strings.Contains(message, key) {
+			t.Errorf("expected message %q to mention parameter %s", message, key)
+		}
+	}
+	if _, ok := fc.Buckets["bucket"]; ok {
+		t.Errorf("expected no bucket to be created")
+	}
+}
+
+func TestCreateBucketEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate bool
+		endpoint string
+		wantCode codes.Code
+	}{
+		{
+			name:     "mismatching endpoint ignored",
+			endpoint: "http://other:9000",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "matching endpoint",
+			validate: true,
+			endpoint: testEndpoint,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "mismatching endpoint",
+			validate: true,
+			endpoint: "http://other:9000",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no endpoint",
+			validate: true,
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, Options{ValidateRequestEndpoint: tt.validate})
+			req := createRequest("bucket", nil)
+			req.Protocol.GetS3().Endpoint = tt.endpoint
+
+			_, err := s.ProvisionerCreateBucket(context.Background(), req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			if _, ok := fc.Buckets["bucket"]; ok != (tt.wantCode == codes.OK) {
+				t.Errorf("expected bucket to exist %v, got %v", tt.wantCode == codes.OK, ok)
+			}
+		})
+	}
+}
+
+func TestCreateBucketDuplicate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantCode codes.Code
+	}{
+		{
+			name:     "existing buckets not adopted",
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "existing buckets adopted",
+			opts:     Options{AdoptExisting: true},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestServer(t, tt.opts)
+			req := createRequest("bucket", nil)
+
+			if _, err := s.ProvisionerCreateBucket(context.Background(), req); err != nil {
+				t.Fatalf("first create failed: %v", err)
+			}
+			resp, err := s.ProvisionerCreateBucket(context.Background(), req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && resp.GetBucketId() != "bucket" {
+				t.Errorf("expected bucket ID %q, got %q", "bucket", resp.GetBucketId())
+			}
+		})
+	}
+}
+
+func TestCreateBucketRetries(t *testing.T) {
+	lost := &url.Error{Op: "Put", URL: testEndpoint + "/bucket", Err: io.ErrUnexpectedEOF}
+	unavailable := min.ErrorResponse{Code: "ServiceUnavailable", StatusCode: http.StatusServiceUnavailable}
+
+	tests := []struct {
+		name       string
+		existing   bool
+		failures   []fake.Failure
+		errors     map[string]error
+		parameters map[string]string
+		wantCode   codes.Code
+		wantBucket bool
+	}{
+		{
+			name:       "fails twice, then succeeds",
+			failures:   []fake.Failure{{Err: lost}, {Err: unavailable}},
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name:       "response of the first attempt lost",
+			failures:   []fake.Failure{{Err: lost, Applied: true}, {Err: lost}},
+			wantCode:   codes.OK,
+			wantBucket: true,
+		},
+		{
+			name:       "existing bucket after a server error",
+			existing:   true,
+			failures:   []fake.Failure{{Err: unavailable}},
+			wantCode:   codes.AlreadyExists,
+			wantBucket: true,
+		},
+		{
+			name:       "option fails on a bucket found after a lost response",
+			failures:   []fake.Failure{{Err: lost, Applied: true}},
+			parameters: map[string]string{minio.TagPrefix + "team": "platform"},
+			errors:     map[string]error{"SetBucketTags": errors.New("boom")},
+			wantCode:   codes.Internal,
+			wantBucket: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, Options{
+				Retry: RetryPolicy{MaxAttempts: 3},
+			})
+			if tt.existing {
+				fc.Buckets["bucket"] = &fake.Bucket{Objects: map[string][]byte{}}
+			}
+			fc.CreateFailures = tt.failures
+			for op, err := range tt.errors {
+				fc.Errors[op] = err
+			}
+
+			resp, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", tt.parameters))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && resp.GetBucketId() != "bucket" {
+				t.Errorf("expected bucket ID %q, got %q", "bucket", resp.GetBucketId())
+			}
+			if len(fc.CreateFailures) != 0 {
+				t.Errorf("expected all failures to be returned, %d left", len(fc.CreateFailures))
+			}
+			if _, ok := fc.Buckets["bucket"]; ok != tt.wantBucket {
+				t.Errorf("expected bucket to exist %v, got %v", tt.wantBucket, ok)
+			}
+			if len(fc.Buckets) > 1 {
+				t.Errorf("expected at most 1 bucket, got %d", len(fc.Buckets))
+			}
+		})
+	}
+}
+
+func TestCreateBucketAppliesOptions(t *testing.T) {
+	s, fc := newTestServer(t, Options{})
+
+	_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", map[string]string{
+		minio.BucketPrefix:             "team-",
+		minio.Encryption:               minio.SSEKMS,
+		minio.KMSKeyID:                 "key",
+		minio.Versioning:               minio.VersioningEnabled,
+		minio.NoncurrentExpireDays:     "7",
+		minio.TagPrefix + "team":       "platform",
+		minio.PlaceholderObject:        ".keep",
+		minio.PlaceholderObjectContent: "hello",
+	}))
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	b, ok := fc.Buckets["team-bucket"]
+	if !ok {
+		t.Fatalf("expected bucket %q to exist", "team-bucket")
+	}
+	if b.Encryption != (minio.EncryptionConfig{Type: minio.SSEKMS, KMSKeyID: "key"}) {
+		t.Errorf("unexpected encryption %+v", b.Encryption)
+	}
+	if !b.Versioning || b.NoncurrentExpiryDays != 7 {
+		t.Errorf("expected versioning with noncurrent expiry of 7 days, got %v and %d", b.Versioning, b.NoncurrentExpiryDays)
+	}
+	if b.Tags["team"] != "platform" {
+		t.Errorf("expected tag team=platform, got %v", b.Tags)
+	}
+	if string(b.Objects[".keep"]) != "hello" {
+		t.Errorf("expected placeholder object with content %q, got %q", "hello", b.Objects[".keep"])
+	}
+}
+
+func TestCreateBucketOptionsPending(t *testing.T) {
+	s, fc := newTestServer(t, Options{})
+	fc.Errors["SetBucketTags"] = errors.New("boom")
+
+	_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", map[string]string{
+		minio.TagPrefix + "team": "platform",
+	}))
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Internal {
+		t.Fatalf("expected code %s, got %v", codes.Internal, err)
+	}
+
+	var retryInfo *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retryInfo = ri
+		}
+	}
+	if retryInfo == nil {
+		t.Fatalf("expected retry info in the details, got %v", st.Details())
+	}
+	if delay := retryInfo.GetRetryDelay().AsDuration(); delay != 5*time.Second {
+		t.Errorf("expected retry delay %s, got %s", 5*time.Second, delay)
+	}
+}
+
+func TestCreateBucketRemovesExpiry(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       bool
+		wantExpiryDays int
+		wantCalls      int
+	}{
+		{
+			name:           "adopted bucket",
+			existing:       true,
+			wantExpiryDays: 0,
+			wantCalls:      1,
+		},
+		{
+			name:      "new bucket",
+			wantCalls: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, Options{AdoptExisting: true})
+			if tt.existing {
+				fc.Buckets["bucket"] = &fake.Bucket{Objects: map[string][]byte{}, ExpiryDays: 30}
+			}
+
+			_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", map[string]string{
+				minio.LifecycleExpiryDays: "0",
+			}))
+			if err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+			if got := fc.Buckets["bucket"].ExpiryDays; got != tt.wantExpiryDays {
+				t.Errorf("expected expiry after %d days, got %d", tt.wantExpiryDays, got)
+			}
+			if got := fc.Calls["SetBucketLifecycle"]; got != tt.wantCalls {
+				t.Errorf("expected %d lifecycle calls, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestCreateBucketDefaultEncryption(t *testing.T) {
+	tests := []struct {
+		name              string
+		defaultEncryption string
+		parameters        map[string]string
+		want              minio.EncryptionConfig
+	}{
+		{
+			name:              "default applied",
+			defaultEncryption: "SSE-KMS:default-key",
+			want:              minio.EncryptionConfig{Type: minio.SSEKMS, KMSKeyID: "default-key"},
+		},
+		{
+			name:              "request overrides default",
+			defaultEncryption: "SSE-KMS:default-key",
+			parameters:        map[string]string{minio.Encryption: minio.SSES3},
+			want:              minio.EncryptionConfig{Type: minio.SSES3},
+		},
+		{
+			name:              "default of none",
+			defaultEncryption: "none",
+		},
+		{
+			name: "no default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, Options{DefaultEncryption: tt.defaultEncryption})
+
+			if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", tt.parameters)); err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+			if got := fc.Buckets["bucket"].Encryption; got != tt.want {
+				t.Errorf("expected encryption %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreateBucketQuota(t *testing.T) {
+	s, fc := newTestServer(t, Options{AdoptExisting: true})
+	req := createRequest("bucket", map[string]string{minio.Quota: "5Gi"})
+
+	if _, err := s.ProvisionerCreateBucket(context.Background(), req); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if q := fc.Buckets["bucket"].Quota; q != 5<<30 {
+		t.Fatalf("expected quota of %d bytes, got %d", 5<<30, q)
+	}
+
+	// A changed class takes effect on the existing bucket
+	req.Parameters[minio.Quota] = "10Gi"
+	if _, err := s.ProvisionerCreateBucket(context.Background(), req); err != nil {
+		t.Fatalf("create of the existing bucket failed: %v", err)
+	}
+	if q := fc.Buckets["bucket"].Quota; q != 10<<30 {
+		t.Errorf("expected quota of %d bytes, got %d", 10<<30, q)
+	}
+}
+
+func TestCreateBucketParameterAliases(t *testing.T) {
+	s, fc := newTestServer(t, Options{
+		ParameterAliases: map[string]string{"versioning.state": minio.Versioning},
+	})
+
+	_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", map[string]string{
+		"object-locking":   "true",
+		"versioning.state": minio.VersioningEnabled,
+	}))
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	b := fc.Buckets["bucket"]
+	if !b.Options.ObjectLocking {
+		t.Errorf("expected object locking from alias %s", "object-locking")
+	}
+	if !b.Versioning {
+		t.Errorf("expected versioning from alias %s", "versioning.state")
+	}
+}
+
+func TestCreateBucketDuplicateParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		wantKeys   []string
+	}{
+		{
+			name: "key and alias",
+			parameters: map[string]string{
+				minio.ObjectLocking: "true",
+				"objectLocking":     "true",
+			},
+			wantKeys: []string{minio.ObjectLocking, "objectLocking"},
+		},
+		{
+			name: "two aliases",
+			parameters: map[string]string{
+				"objectLocking":  "true",
+				"object-locking": "true",
+			},
+			wantKeys: []string{"objectLocking", "object-locking"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, Options{})
+
+			_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", tt.parameters))
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("expected code %s, got %v", codes.InvalidArgument, err)
+			}
+			for _, k := range tt.wantKeys {
+				if !strings.Contains(status.Convert(err).Message(), k) {
+					t.Errorf("expected %s to be named in %q", k, status.Convert(err).Message())
+				}
+			}
+			if len(fc.Buckets) != 0 {
+				t.Errorf("expected no bucket, got %d", len(fc.Buckets))
+			}
+		})
+	}
+}
+
+func TestInvalidParameterAliases(t *testing.T) {
+	for _, aliases := range []map[string]string{
+		{"locking": "objectlocking"},
+		{minio.Versioning: minio.ObjectLocking},
+	} {
+		if _, err := NewProvisionerServer("minio.objectstorage.k8s.io", fake.NewClient(testEndpoint), Options{
+			ParameterAliases: aliases,
+		}); err == nil {
+			t.Errorf("expected aliases %v to be rejected", aliases)
+		}
+	}
+}
+
+func TestMinTLSVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{version: ""},
+		{version: "1.2"},
+		{version: "1.3"},
+		{version: "1.1", wantErr: true},
+		{version: "TLS1.3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			_, err := NewProvisionerServer("minio.objectstorage.k8s.io", fake.NewClient(testEndpoint), Options{
+				ClientOptions: minio.ClientOptions{MinTLSVersion: tt.version},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreateBucketSignatureVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		version  cosi.S3SignatureVersion
+		wantCode codes.Code
+	}{
+		{
+			name:     "allowed",
+			allowed:  []string{"S3V2", "S3V4"},
+			version:  cosi.S3SignatureVersion_S3V2,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "not allowed",
+			allowed:  []string{"S3V4"},
+			version:  cosi.S3SignatureVersion_S3V2,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unset, default allowed",
+			allowed:  []string{"S3V4"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "unset, default not allowed",
+			allowed:  []string{"S3V2"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "empty list, default",
+			version:  cosi.S3SignatureVersion_S3V4,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "empty list, unset",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "empty list, other version",
+			version:  cosi.S3SignatureVersion_S3V2,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestServer(t, Options{AllowedSignatureVersions: tt.allowed})
+			req := createRequest("bucket", nil)
+			req.GetProtocol().GetS3().SignatureVersion = tt.version
+
+			_, err := s.ProvisionerCreateBucket(context.Background(), req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestInvalidSignatureVersion(t *testing.T) {
+	for _, v := range []string{"S3V3", "UnknownSignature"} {
+		if _, err := NewProvisionerServer("minio.objectstorage.k8s.io", fake.NewClient(testEndpoint), Options{
+			AllowedSignatureVersions: []string{v},
+		}); err == nil {
+			t.Errorf("expected signature version %s to be rejected", v)
+		}
+	}
+}
+
+func TestInventoryRoundTrip(t *testing.T) {
+	s, fc := newTestServer(t, Options{})
+	parameters := map[string]string{
+		minio.BucketPrefix:         "team-",
+		minio.ObjectLocking:        "true",
+		minio.ObjectLockMode:       "GOVERNANCE",
+		minio.ObjectLockDays:       "30",
+		minio.Versioning:           minio.VersioningEnabled,
+		minio.Encryption:           minio.SSEKMS,
+		minio.KMSKeyID:             "key",
+		minio.TagPrefix + "team":   "platform",
+		minio.LifecycleExpiryDays:  "90",
+		minio.NoncurrentExpireDays: "7",
+		minio.Quota:                "5Gi",
+	}
+	if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", parameters)); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	// Buckets without the prefix are not exported
+	if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest("other", nil)); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	configs, err := fc.ListBucketConfigs(context.Background(), "team-")
+	if err != nil {
+		t.Fatalf("listing bucket configs failed: %v", err)
+	}
+	out, err := json.Marshal(configs)
+	if err != nil {
+		t.Fatalf("encoding inventory failed: %v", err)
+	}
+	imported := []minio.BucketConfig{}
+	if err := json.Unmarshal(out, &imported); err != nil {
+		t.Fatalf("decoding inventory failed: %v", err)
+	}
+	if len(imported) != 1 || imported[0].Name != "team-bucket" {
+		t.Fatalf("expected only bucket %q to be exported, got %+v", "team-bucket", imported)
+	}
+
+	got, err := minio.ParseBucketParameters(imported[0].Parameters("team-"))
+	if err != nil {
+		t.Fatalf("parsing exported parameters failed: %v", err)
+	}
+	want, err := minio.ParseBucketParameters(parameters)
+	if err != nil {
+		t.Fatalf("parsing parameters failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected parameters %+v, got %+v", want, got)
+	}
+}
+
+func TestBucketPrefix(t *testing.T) {
+	s, fc := newTestServer(t, Options{})
+	parameters := map[string]string{minio.BucketPrefix: "team-"}
+
+	resp, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", parameters))
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if resp.GetBucketId() != "team-bucket" {
+		t.Fatalf("expected bucket ID %q, got %q", "team-bucket", resp.GetBucketId())
+	}
+	if _, ok := fc.Buckets["team-bucket"]; !ok {
+		t.Fatalf("expected bucket %q to exist", "team-bucket")
+	}
+
+	if _, err := s.ProvisionerDeleteBucket(context.Background(), &cosi.ProvisionerDeleteBucketRequest{
+		BucketId: resp.GetBucketId(),
+	}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, ok := fc.Buckets["team-bucket"]; ok {
+		t.Errorf("expected bucket %q to be deleted", "team-bucket")
+	}
+}
+
+func TestBucketIDStableAcrossRestarts(t *testing.T) {
+	opts := Options{AdoptExisting: true}
+	req := createRequest("bucket", map[string]string{
+		minio.BucketPrefix:       "team-",
+		minio.TagPrefix + "team": "platform",
+	})
+
+	fc := fake.NewClient(testEndpoint)
+	ids := []string{}
+	// Each server stands for a run of the driver against
+	// the same MinIO server, where the bucket persists
+	for i := 0; i < 2; i++ {
+		s, err := NewProvisionerServer("minio.objectstorage.k8s.io", fc, opts)
+		if err != nil {
+			t.Fatalf("creating provisioner failed: %v", err)
+		}
+		resp, err := s.ProvisionerCreateBucket(context.Background(), req)
+		if err != nil {
+			t.Fatalf("create %d failed: %v", i, err)
+		}
+		ids = append(ids, resp.GetBucketId())
+	}
+	// A run against another MinIO server with the same config
+	s, _ := newTestServer(t, opts)
+	resp, err := s.ProvisionerCreateBucket(context.Background(), req)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	ids = append(ids, resp.GetBucketId())
+
+	for i, id := range ids {
+		if id != "team-bucket" {
+			t.Errorf("create %d: expected bucket ID %q, got %q", i, "team-bucket", id)
+		}
+	}
+}
+
+func TestBucketPrefixTooLong(t *testing.T) {
+	s, fc := newTestServer(t, Options{})
+	// Both fit the limit of 63 characters on their own
+	parameters := map[string]string{minio.BucketPrefix: strings.Repeat("p", 32)}
+	bucketName := strings.Repeat("b", 32)
+
+	_, err := s.ProvisionerCreateBucket(context.Background(), createRequest(bucketName, parameters))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected code %s, got %v", codes.InvalidArgument, err)
+	}
+	if len(fc.Buckets) > 0 {
+		t.Errorf("expected no bucket to be created, got %d", len(fc.Buckets))
+	}
+}
+
+func TestDeleteBucket(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		bucketID    string
+		bucket      *fake.Bucket
+		errors      map[string]error
+		wantCode    codes.Code
+		wantBucket  bool
+		wantObjects int
+	}{
+		{
+			name:     "empty bucket",
+			bucketID: "bucket",
+			bucket:   &fake.Bucket{},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing bucket",
+			bucketID: "bucket",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing bucket with force delete",
+			opts:     Options{ForceDelete: true},
+			bucketID: "bucket",
+			wantCode: codes.OK,
+		},
+		{
+			name:       "bucket not empty",
+			bucketID:   "bucket",
+			bucket:     &fake.Bucket{Objects: map[string][]byte{"object": nil}},
+			wantCode:   codes.FailedPrecondition,
+			wantBucket: true,
+		},
+		{
+			name:     "bucket not empty with force delete",
+			opts:     Options{ForceDelete: true},
+			bucketID: "bucket",
+			bucket:   &fake.Bucket{Objects: map[string][]byte{"object": nil}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "object under legal hold",
+			opts:     Options{ForceDelete: true},
+			bucketID: "bucket",
+			bucket: &fake.Bucket{
+				Objects:    map[string][]byte{"object": nil},
+				LegalHolds: map[string]bool{"object": true},
+			},
+			wantCode:   codes.FailedPrecondition,
+			wantBucket: true,
+		},
+		{
+			name:     "objects partly under legal hold",
+			opts:     Options{ForceDelete: true},
+			bucketID: "bucket",
+			bucket: &fake.Bucket{
+				Objects:    map[string][]byte{"held": nil, "released": nil, "never-held": nil},
+				LegalHolds: map[string]bool{"held": true, "released": false},
+			},
+			wantCode:    codes.FailedPrecondition,
+			wantBucket:  true,
+			wantObjects: 3,
+		},
+		{
+			name:     "objects with released legal holds",
+			opts:     Options{ForceDelete: true},
+			bucketID: "bucket",
+			bucket: &fake.Bucket{
+				Objects:    map[string][]byte{"released": nil, "never-held": nil},
+				LegalHolds: map[string]bool{"released": false},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:       "deletion fails",
+			bucketID:   "bucket",
+			bucket:     &fake.Bucket{},
+			errors:     map[string]error{"DeleteBucket": errors.New("Access Denied")},
+			wantCode:   codes.Internal,
+			wantBucket: true,
+		},
+		{
+			name:     "empty bucket ID",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, tt.opts)
+			if tt.bucket != nil {
+				fc.Buckets["bucket"] = tt.bucket
+			}
+			for op, err := range tt.errors {
+				fc.Errors[op] = err
+			}
+
+			_, err := s.ProvisionerDeleteBucket(context.Background(), &cosi.ProvisionerDeleteBucketRequest{
+				BucketId: tt.bucketID,
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			b, ok := fc.Buckets["bucket"]
+			if ok != tt.wantBucket {
+				t.Fatalf("expected bucket to exist %v, got %v", tt.wantBucket, ok)
+			}
+			if ok && tt.wantObjects > 0 && len(b.Objects) != tt.wantObjects {
+				t.Errorf("expected %d objects to be kept, got %d", tt.wantObjects, len(b.Objects))
+			}
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	skewed := min.ErrorResponse{Code: "RequestTimeTooSkewed", StatusCode: http.StatusForbidden}
+	tests := []struct {
+		name     string
+		opts     Options
+		existing bool
+		op       string
+		call     func(s *ProvisionerServer) error
+	}{
+		{
+			name: "create",
+			op:   "CreateBucket",
+			call: func(s *ProvisionerServer) error {
+				_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", nil))
+				return err
+			},
+		},
+		{
+			name: "create option",
+			op:   "SetBucketTags",
+			call: func(s *ProvisionerServer) error {
+				_, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", map[string]string{
+					minio.TagPrefix + "team": "platform",
+				}))
+				return err
+			},
+		},
+		{
+			name:     "delete",
+			existing: true,
+			op:       "DeleteBucket",
+			call: func(s *ProvisionerServer) error {
+				_, err := s.ProvisionerDeleteBucket(context.Background(), &cosi.ProvisionerDeleteBucketRequest{BucketId: "bucket"})
+				return err
+			},
+		},
+		{
+			name:     "forced delete",
+			opts:     Options{ForceDelete: true},
+			existing: true,
+			op:       "EmptyBucket",
+			call: func(s *ProvisionerServer) error {
+				_, err := s.ProvisionerDeleteBucket(context.Background(), &cosi.ProvisionerDeleteBucketRequest{BucketId: "bucket"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fc := newTestServer(t, tt.opts)
+			if tt.existing {
+				fc.Buckets["bucket"] = &fake.Bucket{Objects: map[string][]byte{}}
+			}
+			fc.Errors[tt.op] = skewed
+
+			err := tt.call(s)
+			if status.Code(err) != codes.Unavailable {
+				t.Fatalf("expected code %s, got %v", codes.Unavailable, err)
+			}
+			if msg := status.Convert(err).Message(); !strings.Contains(msg, "clock synchronization") {
+				t.Errorf("expected message %q to mention clock synchronization", msg)
+			}
+		})
+	}
+}
+
+func TestGrantBucketAccess(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		req      *cosi.ProvisionerGrantBucketAccessRequest
+		wantCode codes.Code
+	}{
+		{
+			name:     "grant",
+			req:      &cosi.ProvisionerGrantBucketAccessRequest{BucketId: "bucket", AccountName: "account"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "empty bucket ID",
+			req:      &cosi.ProvisionerGrantBucketAccessRequest{AccountName: "account"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "access policy too large",
+			opts: Options{MaxAccessPolicySize: 4},
+			req: &cosi.ProvisionerGrantBucketAccessRequest{
+				BucketId:     "bucket",
+				AccountName:  "account",
+				AccessPolicy: "{\"Effect\":\"Allow\"}",
+			},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestServer(t, tt.opts)
+
+			resp, err := s.ProvisionerGrantBucketAccess(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected code %s, got %v", tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && resp.GetCredentialsFileContents() == "" {
+				t.Errorf("expected credentials in the response")
+			}
+		})
+	}
+}
+
+func TestRevokeBucketAccess(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	_, err := s.ProvisionerRevokeBucketAccess(context.Background(), &cosi.ProvisionerRevokeBucketAccessRequest{
+		BucketId:  "bucket",
+		AccountId: "account",
+	})
+	if err != nil {
+		t.Fatalf("revoke failed: %v", err)
+	}
+}
+
+func TestNewDriverUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	userAgents := map[string]string{}
+	srv := newS3Server(t, func(r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		userAgents[r.Method+" "+r.URL.Path] = r.UserAgent()
+	})
+
+	_, s, err := NewDriver(context.Background(), "minio.objectstorage.k8s.io", srv.URL, "access", "secret", Options{
+		ClientOptions: minio.ClientOptions{AppVersion: "v1.2.3"},
+	})
+	if err != nil {
+		t.Fatalf("creating driver failed: %v", err)
+	}
+	// The quota is set through the admin client
+	if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest("bucket", map[string]string{
+		minio.Quota: "5Gi",
+	})); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	admin := false
+	for request, userAgent := range userAgents {
+		if !strings.Contains(userAgent, "cosi-driver-minio/v1.2.3") {
+			t.Errorf("%s: expected user agent to contain %q, got %q", request, "cosi-driver-minio/v1.2.3", userAgent)
+		}
+		admin = admin || strings.Contains(request, "/minio/admin/")
+	}
+	if !admin {
+		t.Errorf("expected requests of the admin client, got %v", userAgents)
+	}
+}
+
+func TestNewDriverMaxConnsPerHost(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	srv := newS3Server(t, func(r *http.Request) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	})
+
+	_, s, err := NewDriver(context.Background(), "minio.objectstorage.k8s.io", srv.URL, "access", "secret", Options{
+		ClientOptions: minio.ClientOptions{MaxConnsPerHost: 1},
+	})
+	if err != nil {
+		t.Fatalf("creating driver failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bucketName := fmt.Sprintf("bucket-%d", i)
+			if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest(bucketName, nil)); err != nil {
+				t.Errorf("create of %s failed: %v", bucketName, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if peak > 1 {
+		t.Errorf("expected at most 1 request to MinIO at a time, got %d", peak)
+	}
+}
+
+func TestNewDriverCorrelationID(t *testing.T) {
+	var mu sync.Mutex
+	ids := map[string]map[string]bool{}
+	srv := newS3Server(t, func(r *http.Request) {
+		if r.Method != http.MethodPut {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		bucketName := strings.Trim(r.URL.Path, "/")
+		if strings.HasPrefix(r.URL.Path, "/minio/admin/") {
+			bucketName = r.URL.Query().Get("bucket")
+		}
+		if ids[bucketName] == nil {
+			ids[bucketName] = map[string]bool{}
+		}
+		ids[bucketName][r.Header.Get(minio.CorrelationIDHeader)] = true
+	})
+
+	_, s, err := NewDriver(context.Background(), "minio.objectstorage.k8s.io", srv.URL, "access", "secret", Options{})
+	if err != nil {
+		t.Fatalf("creating driver failed: %v", err)
+	}
+	for _, bucketName := range []string{"bucket-a", "bucket-b"} {
+		if _, err := s.ProvisionerCreateBucket(context.Background(), createRequest(bucketName, map[string]string{
+			minio.Quota: "5Gi",
+		})); err != nil {
+			t.Fatalf("create of %s failed: %v", bucketName, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The requests of a create share a correlation ID, which
+	// differs from the one of any other create
+	seen := map[string]bool{}
+	for _, bucketName := range []string{"bucket-a", "bucket-b"} {
+		if len(ids[bucketName]) != 1 {
+			t.Fatalf("%s: expected requests to carry one correlation ID, got %v", bucketName, ids[bucketName])
+		}
+		for id := range ids[bucketName] {
+			if id == "" || seen[id] {
+				t.Errorf("%s: expected a new correlation ID, got %q", bucketName, id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestNewDriverWarmUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		hang    bool
+		wantErr bool
+	}{
+		{
+			name:  "slow server",
+			delay: 100 * time.Millisecond,
+		},
+		{
+			name:    "hanging server",
+			hang:    true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			var mu sync.Mutex
+			warmedUp := false
+			srv := newS3Server(t, func(r *http.Request) {
+				if tt.hang {
+					<-release
+				}
+				time.Sleep(tt.delay)
+				mu.Lock()
+				warmedUp = true
+				mu.Unlock()
+			})
+			// Runs ahead of closing the server, which waits for
+			// the hanging request
+			t.Cleanup(func() { close(release) })
+
+			start := time.Now()
+			_, _, err := NewDriver(context.Background(), "minio.objectstorage.k8s.io", srv.URL, "access", "secret", Options{
+				ConnectTimeout: 500 * time.Millisecond,
+			})
+			elapsed := time.Since(start)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if elapsed > 2*time.Second {
+				t.Errorf("expected startup to be bounded by the connect timeout, took %s", elapsed)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// The driver is only handed out, and so served,
+			// once the connection has been validated
+			mu.Lock()
+			defer mu.Unlock()
+			if !warmedUp || elapsed < tt.delay {
+				t.Errorf("expected the driver after the warm-up, got it after %s", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cosi-driver-minio/pkg/minio"
+)
+
+// RetryPolicy bounds the retries of MinIO calls that fail
+// with transient errors, such as timeouts, throttling or
+// server errors
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts made, including
+	// the first one. Each attempt is retried by the MinIO client
+	// on its own first. Values below 2 leave retries to the
+	// MinIO client
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It
+	// doubles with every further retry
+	BaseDelay time.Duration
+}
+
+// retryAfter waits out the delay before a retry. Tests replace it
+var retryAfter = time.After
+
+// do calls fn until it succeeds, fails with an error that is not
+// transient, or the attempts are used up. The last error is returned
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	delay := p.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !minio.IsTransient(err) {
+			return err
+		}
+
+		klog.V(3).InfoS("Retrying MinIO call after transient error", "attempt", attempt, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-retryAfter(delay):
+		}
+		delay *= 2
+	}
+}
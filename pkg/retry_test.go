@@ -0,0 +1,98 @@
+// Copyright 2021 The Kubernetes Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	transient := &url.Error{Op: "Put", URL: "http://minio:9000/bucket", Err: io.ErrUnexpectedEOF}
+
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		errs       []error
+		wantCalls  int
+		wantDelays []time.Duration
+		wantErr    error
+	}{
+		{
+			name:       "transient errors retried up to the attempts",
+			policy:     RetryPolicy{MaxAttempts: 4, BaseDelay: time.Second},
+			errs:       []error{transient, transient, transient, transient, transient},
+			wantCalls:  4,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+			wantErr:    transient,
+		},
+		{
+			name:       "success after transient errors",
+			policy:     RetryPolicy{MaxAttempts: 4},
+			errs:       []error{transient, transient, nil},
+			wantCalls:  3,
+			wantDelays: []time.Duration{0, 0},
+		},
+		{
+			name:      "other errors not retried",
+			policy:    RetryPolicy{MaxAttempts: 4},
+			errs:      []error{errors.New("boom")},
+			wantCalls: 1,
+			wantErr:   errors.New("boom"),
+		},
+		{
+			name:      "retries left to the minio client",
+			errs:      []error{transient},
+			wantCalls: 1,
+			wantErr:   transient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := []time.Duration{}
+			retryAfter = func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				return time.After(0)
+			}
+			defer func() { retryAfter = time.After }()
+
+			calls := 0
+			err := tt.policy.do(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls)
+			}
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(tt.wantDelays) > 0 && !reflect.DeepEqual(delays, tt.wantDelays) {
+				t.Errorf("expected delays %v, got %v", tt.wantDelays, delays)
+			}
+			if len(tt.wantDelays) == 0 && len(delays) > 0 {
+				t.Errorf("expected no retries, got delays %v", delays)
+			}
+		})
+	}
+}